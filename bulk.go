@@ -0,0 +1,249 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// DefaultBulkConcurrency is the most requests a bulk operation sends at once when BulkOptions.Concurrency isn't set.
+const DefaultBulkConcurrency = 4
+
+// ErrBulkAborted is the error of the items of an all or nothing bulk operation that weren't attempted because another
+// item failed.
+var ErrBulkAborted = errors.New("bulk operation aborted")
+
+// BulkMode is how a bulk operation handles an item that fails.
+type BulkMode int
+
+const (
+	// BulkContinueOnError attempts every item, the items that fail are reported in the results.
+	BulkContinueOnError BulkMode = iota
+	// BulkAllOrNothing stops at the first item that fails and undoes the items that succeeded.
+	BulkAllOrNothing
+)
+
+// BulkOptions changes how a bulk operation is run.
+type BulkOptions struct {
+	Mode BulkMode
+	// Concurrency is the most requests sent at once. If zero, DefaultBulkConcurrency is used, lowered to the number of
+	// requests the shared rate limit has remaining so the workers don't all wait for the limit to reset.
+	Concurrency int
+	// Progress is called after each item is finished with its result and how many of the items are done. Calls are
+	// never made concurrently.
+	Progress func(result BulkResult, done, total int)
+}
+
+// BulkResult is the result of a single item of a bulk operation.
+type BulkResult struct {
+	// Index is the position of the item in the slice passed to the bulk operation.
+	Index int
+	// Id is the id of the created or removed record.
+	Id  string
+	Err error
+	// RolledBack is set when the item succeeded but was undone because another item of an all or nothing operation
+	// failed.
+	RolledBack bool
+	// RollbackErr is the error undoing the item, set when the item succeeded but couldn't be undone.
+	RollbackErr error
+}
+
+// BulkError is returned by a bulk operation when any of its items fail.
+type BulkError struct {
+	// Failed is the result of every item that failed, in the order of the items.
+	Failed []BulkResult
+	// RollbackFailed is the result of every item of an all or nothing operation that succeeded but couldn't be undone,
+	// these items are left changed.
+	RollbackFailed []BulkResult
+	Total          int
+}
+
+func (e *BulkError) Error() string {
+	message := fmt.Sprintf("%d of %d bulk operations failed, first error: %s", len(e.Failed), e.Total, e.Failed[0].Err)
+	if len(e.RollbackFailed) > 0 {
+		message = fmt.Sprintf("%s, %d could not be rolled back, first error: %s", message, len(e.RollbackFailed),
+			e.RollbackFailed[0].RollbackErr)
+	}
+	return message
+}
+
+// Unwrap returns the error of the first item that failed.
+func (e *BulkError) Unwrap() error {
+	return e.Failed[0].Err
+}
+
+// BulkCreateDNSRecords will create the records of the domain using a pool of workers. The results are in the order of
+// the records and hold the id of each created record. If any record fails a *BulkError is returned along with the
+// results, in BulkAllOrNothing mode the records that were created are removed again. Records that couldn't be removed
+// have their RollbackErr set and are listed in the RollbackFailed of the *BulkError.
+func (c Client) BulkCreateDNSRecords(domain string, records []Record, opts BulkOptions) ([]BulkResult, error) {
+	return c.BulkCreateDNSRecordsContext(context.Background(), domain, records, opts)
+}
+
+// BulkCreateDNSRecordsContext is the same as BulkCreateDNSRecords but the requests are bound to the given context.
+func (c Client) BulkCreateDNSRecordsContext(ctx context.Context, domain string, records []Record, opts BulkOptions) ([]BulkResult, error) {
+	results := c.bulk(ctx, len(records), opts, func(ctx context.Context, i int) (string, error) {
+		record := records[i]
+		return c.CreateDNSRecordContext(ctx, domain, &record)
+	})
+	err := bulkError(results)
+	if err != nil && opts.Mode == BulkAllOrNothing {
+		// the rollback isn't bound to the context so it still runs when the operation failed because it was cancelled
+		c.bulk(context.Background(), len(results), opts.rollback(), func(ctx context.Context, i int) (string, error) {
+			if results[i].Err != nil {
+				return "", nil
+			}
+			if err := c.RemoveDNSRecordContext(ctx, domain, results[i].Id); err != nil {
+				results[i].RollbackErr = err
+				return "", err
+			}
+			results[i].RolledBack = true
+			return "", nil
+		})
+		err = bulkError(results)
+	}
+	return results, err
+}
+
+// BulkRemoveDNSRecords will remove the records of the domain with the given ids using a pool of workers. If any record
+// fails a *BulkError is returned along with the results. In BulkAllOrNothing mode the records are listed first so the
+// records that were removed can be created again if another fails, recreated records are given new ids. Records that
+// couldn't be created again have their RollbackErr set and are listed in the RollbackFailed of the *BulkError.
+func (c Client) BulkRemoveDNSRecords(domain string, ids []string, opts BulkOptions) ([]BulkResult, error) {
+	return c.BulkRemoveDNSRecordsContext(context.Background(), domain, ids, opts)
+}
+
+// BulkRemoveDNSRecordsContext is the same as BulkRemoveDNSRecords but the requests are bound to the given context.
+func (c Client) BulkRemoveDNSRecordsContext(ctx context.Context, domain string, ids []string, opts BulkOptions) ([]BulkResult, error) {
+	existing := make(map[string]Record)
+	if opts.Mode == BulkAllOrNothing {
+		records, err := c.ListDNSRecordsContext(ctx, domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range records {
+			existing[record.Id] = record
+		}
+	}
+
+	results := c.bulk(ctx, len(ids), opts, func(ctx context.Context, i int) (string, error) {
+		return ids[i], c.RemoveDNSRecordContext(ctx, domain, ids[i])
+	})
+	err := bulkError(results)
+	if err != nil && opts.Mode == BulkAllOrNothing {
+		c.bulk(context.Background(), len(results), opts.rollback(), func(ctx context.Context, i int) (string, error) {
+			record, ok := existing[results[i].Id]
+			if results[i].Err != nil || !ok {
+				return "", nil
+			}
+			if _, err := c.CreateDNSRecordContext(ctx, domain, &Record{
+				Name:        record.Name,
+				Type:        record.Type,
+				Value:       record.Value,
+				TTL:         record.TTL,
+				MxPriority:  record.MxPriority,
+				SrvPriority: record.SrvPriority,
+			}); err != nil {
+				results[i].RollbackErr = err
+				return "", err
+			}
+			results[i].RolledBack = true
+			return "", nil
+		})
+		err = bulkError(results)
+	}
+	return results, err
+}
+
+// bulk runs the operation for each of the n items on a pool of workers and returns the result of each item. In
+// BulkAllOrNothing mode the items that haven't started when one fails are not attempted, the items already running are
+// left to finish so their result is known.
+func (c Client) bulk(ctx context.Context, n int, opts BulkOptions, operation func(ctx context.Context, i int) (string, error)) []BulkResult {
+	results := make([]BulkResult, n)
+	items := make(chan int)
+	var mutex sync.Mutex
+	done := 0
+	aborted := false
+	start := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return !aborted
+	}
+	finish := func(result BulkResult) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		results[result.Index] = result
+		done++
+		if result.Err != nil && opts.Mode == BulkAllOrNothing {
+			aborted = true
+		}
+		if opts.Progress != nil {
+			opts.Progress(result, done, n)
+		}
+	}
+
+	var wg sync.WaitGroup
+	for w := 0; w < c.bulkConcurrency(opts, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range items {
+				if !start() {
+					finish(BulkResult{Index: i, Err: ErrBulkAborted})
+					continue
+				}
+				id, err := operation(ctx, i)
+				finish(BulkResult{Index: i, Id: id, Err: err})
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		items <- i
+	}
+	close(items)
+	wg.Wait()
+	return results
+}
+
+// bulkConcurrency returns the number of workers to use for n items.
+func (c Client) bulkConcurrency(opts BulkOptions, n int) int {
+	workers := opts.Concurrency
+	if workers <= 0 {
+		workers = DefaultBulkConcurrency
+		// the rate limit isn't known until a response has been received
+		if state := c.RateLimit(); state.Limit > 1 && state.Remaining < workers {
+			workers = state.Remaining
+		}
+	}
+	if workers > n {
+		workers = n
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return workers
+}
+
+// rollback returns the options used to undo an all or nothing operation, every item is attempted and progress isn't
+// reported.
+func (opts BulkOptions) rollback() BulkOptions {
+	return BulkOptions{Mode: BulkContinueOnError, Concurrency: opts.Concurrency}
+}
+
+// bulkError returns a *BulkError if any of the items failed.
+func bulkError(results []BulkResult) error {
+	var failed, rollbackFailed []BulkResult
+	for _, result := range results {
+		if result.Err != nil {
+			failed = append(failed, result)
+		}
+		if result.RollbackErr != nil {
+			rollbackFailed = append(rollbackFailed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return &BulkError{Failed: failed, RollbackFailed: rollbackFailed, Total: len(results)}
+}
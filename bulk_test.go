@@ -0,0 +1,176 @@
+package zeit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBulkServer() (*zeittest.Server, *Client) {
+	server := zeittest.NewServer()
+	server.AddDomain("", "example.com")
+	return server, NewClient(server.Token, WithBaseURL(server.URL))
+}
+
+func bulkRecords(n int) []Record {
+	records := make([]Record, n)
+	for i := range records {
+		records[i] = Record{Name: fmt.Sprintf("host%d", i), Type: RecordTypeA, Value: "1.1.1.1"}
+	}
+	return records
+}
+
+func TestClient_BulkCreateDNSRecords(t *testing.T) {
+	a := assert.New(t)
+	server, client := newBulkServer()
+	defer server.Close()
+
+	var mutex sync.Mutex
+	var progress []int
+	results, err := client.BulkCreateDNSRecords("example.com", bulkRecords(20), BulkOptions{
+		Progress: func(result BulkResult, done, total int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			a.Equal(20, total)
+			progress = append(progress, done)
+		},
+	})
+	a.Nil(err, "Error should be nil")
+	a.Len(results, 20)
+	a.Len(progress, 20, "progress should be reported for every record")
+	a.Equal(20, progress[19])
+
+	records := server.Records("", "example.com")
+	a.Len(records, 20)
+	ids := make(map[string]bool)
+	for _, record := range records {
+		ids[record.Id] = true
+	}
+	for i, result := range results {
+		a.Equal(i, result.Index)
+		a.True(ids[result.Id], "result should hold the id of the created record")
+	}
+}
+
+func TestClient_BulkCreateDNSRecords_ContinueOnError(t *testing.T) {
+	a := assert.New(t)
+	server, client := newBulkServer()
+	defer server.Close()
+
+	records := bulkRecords(10)
+	records[3].Value = "not an address"
+	results, err := client.BulkCreateDNSRecords("example.com", records, BulkOptions{Mode: BulkContinueOnError})
+
+	var bulkError *BulkError
+	a.True(errors.As(err, &bulkError), "should return a BulkError")
+	a.Len(bulkError.Failed, 1)
+	a.Equal(3, bulkError.Failed[0].Index)
+	var validationError *ValidationError
+	a.True(errors.As(err, &validationError), "should unwrap to the error of the item")
+	a.Error(results[3].Err)
+	a.Len(server.Records("", "example.com"), 9, "other records should be created")
+}
+
+func TestClient_BulkCreateDNSRecords_AllOrNothing(t *testing.T) {
+	a := assert.New(t)
+	server, client := newBulkServer()
+	defer server.Close()
+
+	records := bulkRecords(10)
+	records[5].Value = "not an address"
+	results, err := client.BulkCreateDNSRecords("example.com", records, BulkOptions{Mode: BulkAllOrNothing, Concurrency: 2})
+	a.Error(err)
+	a.Empty(server.Records("", "example.com"), "created records should be removed")
+	for i, result := range results {
+		if i == 5 {
+			continue
+		}
+		a.True(result.RolledBack || errors.Is(result.Err, ErrBulkAborted), "record %d should be undone or not attempted", i)
+	}
+}
+
+func TestClient_BulkRemoveDNSRecords(t *testing.T) {
+	a := assert.New(t)
+	server, client := newBulkServer()
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 10; i++ {
+		id, err := server.AddRecord("", "example.com", zeittest.Record{Name: fmt.Sprintf("host%d", i), Type: RecordTypeA, Value: "1.1.1.1"})
+		a.Nil(err)
+		ids = append(ids, id)
+	}
+
+	t.Run("all or nothing", func(t *testing.T) {
+		results, err := client.BulkRemoveDNSRecords("example.com", append(ids[:5:5], "missing"), BulkOptions{Mode: BulkAllOrNothing})
+		a.True(errors.Is(err, ErrNotFound), "should return the error of the missing record")
+		a.Len(results, 6)
+		a.Len(server.Records("", "example.com"), 10, "removed records should be recreated")
+	})
+
+	t.Run("continue on error", func(t *testing.T) {
+		records, err := client.ListDNSRecords("example.com")
+		a.Nil(err)
+		ids := []string{"missing"}
+		for _, record := range records {
+			ids = append(ids, record.Id)
+		}
+		results, err := client.BulkRemoveDNSRecords("example.com", ids, BulkOptions{})
+		var bulkError *BulkError
+		a.True(errors.As(err, &bulkError), "should return a BulkError")
+		a.Equal(11, bulkError.Total)
+		a.Len(bulkError.Failed, 1)
+		a.Equal(ids[1], results[1].Id)
+		a.Empty(server.Records("", "example.com"))
+	})
+}
+
+func TestClient_BulkRemoveDNSRecords_RollbackError(t *testing.T) {
+	a := assert.New(t)
+	server, client := newBulkServer()
+	defer server.Close()
+
+	var ids []string
+	for i := 0; i < 3; i++ {
+		id, err := server.AddRecord("", "example.com", zeittest.Record{Name: fmt.Sprintf("host%d", i), Type: RecordTypeA, Value: "1.1.1.1"})
+		a.Nil(err)
+		ids = append(ids, id)
+	}
+	// records can be removed but not created again
+	server.Handle(http.MethodPost, "/v2/domains/example.com/records", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"code": "forbidden", "message": "Not allowed"}}`))
+	})
+
+	results, err := client.BulkRemoveDNSRecords("example.com", append(ids, "missing"), BulkOptions{Mode: BulkAllOrNothing, Concurrency: 1})
+	var bulkError *BulkError
+	a.True(errors.As(err, &bulkError), "should return a BulkError")
+	a.True(errors.Is(err, ErrNotFound), "should unwrap to the error of the item")
+	a.Len(bulkError.Failed, 1)
+	a.Len(bulkError.RollbackFailed, 3, "records that weren't recreated should be reported")
+	a.Contains(err.Error(), "3 could not be rolled back")
+	for _, result := range results[:3] {
+		a.False(result.RolledBack)
+		a.True(errors.Is(result.RollbackErr, ErrForbidden), "rollback error should be recorded")
+	}
+	a.Nil(results[3].RollbackErr)
+	a.Empty(server.Records("", "example.com"))
+}
+
+func TestClient_bulkConcurrency(t *testing.T) {
+	a := assert.New(t)
+	client := Client{rateLimit: &rateLimit{limit: 1, remaining: 1}}
+	a.Equal(DefaultBulkConcurrency, client.bulkConcurrency(BulkOptions{}, 100), "unknown limit should use the default")
+	a.Equal(2, client.bulkConcurrency(BulkOptions{}, 2), "workers should not outnumber items")
+
+	client.rateLimit = &rateLimit{limit: 100, remaining: 2}
+	a.Equal(2, client.bulkConcurrency(BulkOptions{}, 100), "workers should not outnumber remaining requests")
+	client.rateLimit = &rateLimit{limit: 100, remaining: 0}
+	a.Equal(1, client.bulkConcurrency(BulkOptions{}, 100))
+	a.Equal(10, client.bulkConcurrency(BulkOptions{Concurrency: 10}, 100), "set concurrency should be used")
+}
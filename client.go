@@ -1,32 +1,29 @@
 package zeit
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
 	"net/http"
-	"strconv"
-	"sync"
 	"time"
 )
 
-type rateLimit struct {
-	limit     int
-	remaining int
-	reset     time.Time
-	mutex     sync.Mutex
-}
-
 type Client struct {
-	token      string
-	rootUrl    string
-	httpClient HttpClient
-	rateLimit  *rateLimit
-	team       string
+	token       string
+	rootUrl     string
+	httpClient  HttpClient
+	rateLimit   *rateLimit
+	rateLimiter RateLimiter
+	retryPolicy RetryPolicy
+	team        string
+	userAgent   string
+	timeout     time.Duration
 }
 
-var rateLimits = make(map[string]*rateLimit)
+const defaultRootUrl = "https://api.zeit.co"
 
 //go:generate mockgen -destination=mocks/mock_http_client.go -package=mocks github.com/kochie/zeit-api-go HttpClient
 
@@ -35,108 +32,203 @@ type HttpClient interface {
 }
 
 // NewClient will create a new zeit client to apply api request to. Note that the team is defaulted to nothing, if you
-// want to update the team then use Team.
-func NewClient(token string) *Client {
-	var rl *rateLimit
-	if val, ok := rateLimits[token]; ok {
-		rl = val
-	} else {
-		rl = &rateLimit{1, 1, time.Now(), sync.Mutex{}}
-	}
-	return &Client{
-		token,
-		"https://api.zeit.co",
-		&http.Client{},
-		rl,
-		"",
+// want to update the team then use Team or the WithTeam option. The client can be further configured by passing any
+// number of options, these are applied in order.
+func NewClient(token string, opts ...Option) *Client {
+	c := &Client{
+		token:      token,
+		rootUrl:    defaultRootUrl,
+		httpClient: &http.Client{},
+		rateLimit:  sharedRateLimit(token),
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.timeout > 0 {
+		if httpClient, ok := c.httpClient.(*http.Client); ok {
+			withTimeout := *httpClient
+			withTimeout.Timeout = c.timeout
+			c.httpClient = &withTimeout
+		}
+	}
+	return c
 }
 
-// Team will set the team associated with the api client, to not use a team set with empty string.
-func (c Client) Team(team string) {
+// Team will set the team associated with the api client, to not use a team set with empty string. The client is changed
+// in place, use WithTeam to get a separately scoped copy instead.
+func (c *Client) Team(team string) {
 	c.team = team
 }
 
-// closeResponseBody is a helper function to close the body of a http response and panic if there is an error closing
-// the io writer.
-func closeResponseBody(resp *http.Response) {
-	if err := resp.Body.Close(); err != nil {
-		panic("http response couldn't be closed")
+// WithTeam will return a copy of the client scoped to the given team, the original client is left unchanged. Passing
+// an empty string returns a copy scoped to the personal account. Both clients share the same rate limit.
+func (c *Client) WithTeam(team string) *Client {
+	scoped := *c
+	scoped.team = team
+	return &scoped
+}
+
+type teamContextKey struct{}
+
+// ContextWithTeam returns a context that overrides the team of any request made with it, regardless of the team the
+// client is scoped to. Use an empty string to make the request against the personal account.
+func ContextWithTeam(ctx context.Context, team string) context.Context {
+	return context.WithValue(ctx, teamContextKey{}, team)
+}
+
+// teamFromContext returns the team the request should be scoped to, the team set with ContextWithTeam takes precedence
+// over the team of the client.
+func (c Client) teamFromContext(ctx context.Context) string {
+	if team, ok := ctx.Value(teamContextKey{}).(string); ok {
+		return team
 	}
+	return c.team
 }
 
 // makeAndDoRequest will create the appropriate request and then send it to the endpoint specified. It will handle
-// authentication, headers, and rate limiting.
-func (c Client) makeAndDoRequest(httpMethod, endpoint string, body io.Reader) (*http.Response, error) {
+// authentication, headers, rate limiting and retries. Any header given is added to the request, a Content-Type in it
+// replaces the default of application/json. The context is attached to the request and is also used to abort any wait
+// for the rate limit to reset or between retries.
+func (c Client) makeAndDoRequest(ctx context.Context, httpMethod, endpoint string, body io.Reader, header http.Header) (*http.Response, error) {
+	// the body is buffered so it can be sent again if the request is retried
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = ioutil.ReadAll(body); err != nil {
+			return nil, err
+		}
+	}
+
+	policy := c.retry()
+	retryable := isIdempotent(httpMethod) || policy.RetryNonIdempotent || idempotentFromContext(ctx)
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.newRequest(ctx, httpMethod, endpoint, payload, body != nil, header)
+		if err != nil {
+			return nil, err
+		}
+
+		// wait until the rate limiter allows another request
+		if err := c.limiter().Wait(ctx, c.rateLimit.reserve()); err != nil {
+			return nil, err
+		}
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !retryable || attempt >= policy.MaxAttempts || !isTemporaryError(err) {
+				return nil, err
+			}
+			if err := sleepContext(ctx, policy.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		c.rateLimit.updateFromHeaders(resp.Header)
+		if resp.Request == nil {
+			resp.Request = req
+		}
+
+		switch {
+		case resp.StatusCode == http.StatusTooManyRequests:
+			// the request was rejected without being processed so it is always safe to send again, the limit reported
+			// in the body is recorded so the rate limiter waits for it to reset
+			if err := c.recordRateLimitError(resp); err != nil {
+				return nil, err
+			}
+			if attempt >= policy.MaxAttempts {
+				return resp, nil
+			}
+			wait, _ := retryAfter(resp.Header)
+			closeResponseBody(resp)
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
+		case isRetryableStatus(resp.StatusCode) && retryable && attempt < policy.MaxAttempts:
+			wait, ok := retryAfter(resp.Header)
+			if !ok {
+				wait = policy.backoff(attempt)
+			}
+			closeResponseBody(resp)
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
+		default:
+			return resp, nil
+		}
+	}
+}
+
+// newRequest will create a single attempt of a request with the authentication, headers and team set.
+func (c Client) newRequest(ctx context.Context, httpMethod, endpoint string, payload []byte, hasBody bool, header http.Header) (*http.Request, error) {
 	url := fmt.Sprintf("%s/%s", c.rootUrl, endpoint)
-	req, err := http.NewRequest(httpMethod, url, body)
+	var body io.Reader
+	if hasBody {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpMethod, url, body)
 	if err != nil {
 		return nil, err
 	}
-	if body != nil {
+	if hasBody {
 		req.Header.Add("Content-Type", "application/json")
 	}
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.token))
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
+	for key, values := range header {
+		req.Header[http.CanonicalHeaderKey(key)] = values
+	}
 
 	// if team is defined, add it to the url query
-	if c.team != "" {
+	if team := c.teamFromContext(ctx); team != "" {
 		q := req.URL.Query()
-		q.Add("teamId", c.team)
+		q.Add("teamId", team)
 		req.URL.RawQuery = q.Encode()
 	}
-	mutex := c.rateLimit.mutex
-
-	// do a check to see if the rate limit has been hit, if so wait until a request can be sent again
-doRequest:
-	mutex.Lock()
-	remaining := c.rateLimit.remaining
-	reset := c.rateLimit.reset
-	mutex.Unlock()
-	now := time.Now()
-	if remaining == 0 && now.Before(reset) {
-		d := reset.Sub(now)
-		log.Printf("Zeit rate limit hit, waiting for %s", d.String())
-		time.Sleep(d)
-	}
-	resp, err := c.httpClient.Do(req)
+	return req, nil
+}
+
+// recordRateLimitError will record the rate limit sent in the body of a 429 response. The body is replaced so it can
+// still be read by the caller.
+func (c Client) recordRateLimitError(resp *http.Response) error {
+	data, err := ioutil.ReadAll(resp.Body)
+	closeResponseBody(resp)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(data))
 
-	if resp.StatusCode == http.StatusTooManyRequests {
-		rateLimitError := RateLimitError{}
-		err := json.NewDecoder(resp.Body).Decode(&struct {
-			Error *RateLimitError `json:"error"`
-		}{&rateLimitError})
-		if err != nil {
-			return nil, err
-		}
-		mutex.Lock()
-		c.rateLimit.remaining = rateLimitError.Limit.Remaining
-		c.rateLimit.reset = time.Unix(rateLimitError.Limit.Reset, 0)
-		c.rateLimit.limit = rateLimitError.Limit.Total
-		mutex.Unlock()
-
-		goto doRequest
+	rateLimitError := RateLimitError{APIError: &APIError{}}
+	err = json.Unmarshal(data, &struct {
+		Error *RateLimitError `json:"error"`
+	}{&rateLimitError})
+	if err != nil {
+		return err
 	}
+	c.rateLimit.updateFromError(rateLimitError)
+	return nil
+}
 
-	mutex.Lock()
-	if RateLimitRemaining := resp.Header.Get("X-RateLimit-Remaining"); RateLimitRemaining != "" {
-		if remaining, err := strconv.ParseInt(RateLimitRemaining, 10, 32); err != nil {
-			c.rateLimit.remaining = int(remaining)
-		}
+// limiter returns the rate limiter used by the client, if none has been set the HeaderRateLimiter is used.
+func (c Client) limiter() RateLimiter {
+	if c.rateLimiter == nil {
+		return &HeaderRateLimiter{}
 	}
-	if RateLimitLimit := resp.Header.Get("X-RateLimit-Limit"); RateLimitLimit != "" {
-		if limit, err := strconv.ParseInt(RateLimitLimit, 10, 32); err != nil {
-			c.rateLimit.limit = int(limit)
-		}
-	}
-	if RateLimitReset := resp.Header.Get("X-RateLimit-Reset"); RateLimitReset != "" {
-		if reset, err := strconv.ParseInt(RateLimitReset, 10, 64); err != nil {
-			c.rateLimit.reset = time.Unix(reset, 0)
-		}
-	}
-	mutex.Unlock()
+	return c.rateLimiter
+}
 
-	return resp, nil
+// sleepContext pauses the current goroutine for at least the duration d, or until the context is done. If the context
+// finishes first its error is returned.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
 }
@@ -1,10 +1,13 @@
 package zeit
 
 import (
+	"context"
 	"github.com/golang/mock/gomock"
 	"github.com/kochie/zeit-api-go/mocks"
 	"github.com/stretchr/testify/assert"
+	"net/http"
 	"testing"
+	"time"
 )
 
 func TestNewClient(t *testing.T) {
@@ -30,3 +33,101 @@ func TestRateLimit(t *testing.T) {
 
 	a.NotNil(client)
 }
+
+func TestClient_ContextCancelledDuringRateLimit(t *testing.T) {
+	a := assert.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{remaining: 0, reset: time.Now().Add(time.Hour)},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.ListAllDomainsContext(ctx)
+	a.Equal(context.DeadlineExceeded, err, "should stop waiting when the context is done")
+	a.True(time.Since(start) < time.Minute, "should not wait for the rate limit to reset")
+}
+
+func TestClient_RequestUsesContext(t *testing.T) {
+	a := assert.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "value")
+
+	httpResponse := makeResponse([]byte(`{"domains":[]}`), http.StatusOK)
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		a.Equal("value", req.Context().Value(key{}), "request should carry the context")
+		return &httpResponse, nil
+	})
+
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	_, err := client.ListAllDomainsContext(ctx)
+	a.Nil(err, "Error should be nil")
+}
+
+func TestClient_Team(t *testing.T) {
+	a := assert.New(t)
+
+	client := NewClient(TestToken)
+	client.Team("team_123")
+	a.Equal("team_123", client.team, "team should be set on the client")
+
+	scoped := client.WithTeam("team_456")
+	a.Equal("team_456", scoped.team, "copy should be scoped to the new team")
+	a.Equal("team_123", client.team, "original client should keep its team")
+	a.Equal(client.rateLimit, scoped.rateLimit, "copy should share the rate limit")
+}
+
+func TestClient_TeamOverride(t *testing.T) {
+	a := assert.New(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+
+	client := NewClient(TestToken, WithBaseURL(rootUrl), WithHTTPClient(mockHttpClient), WithTeam("team_123"))
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		team string
+	}{
+		{"client team", context.Background(), "team_123"},
+		{"override team", ContextWithTeam(context.Background(), "team_456"), "team_456"},
+		{"personal", ContextWithTeam(context.Background(), ""), ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			httpResponse := makeResponse([]byte(`{"domains":[]}`), http.StatusOK)
+			mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+				_, hasTeam := req.URL.Query()["teamId"]
+				a.Equal(test.team != "", hasTeam, "teamId should only be sent when there is a team")
+				a.Equal(test.team, req.URL.Query().Get("teamId"), "request should be scoped to the team")
+				return &httpResponse, nil
+			})
+
+			_, err := client.ListAllDomainsContext(test.ctx)
+			a.Nil(err, "Error should be nil")
+		})
+	}
+}
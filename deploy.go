@@ -0,0 +1,263 @@
+package zeit
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/kochie/zeit-api-go/ignore"
+)
+
+// DefaultIgnore is the patterns of the files that are never deployed, they are applied before the patterns of the
+// .nowignore file so it can re-include them with a negated pattern.
+var DefaultIgnore = []string{
+	".hg", ".git", ".gitmodules", ".svn", "CVS", ".cache", ".next", ".now", ".venv", "venv", "__pycache__",
+	"node_modules", "npm-debug.log", "config.gypi", ".npmignore", ".dockerignore", ".gitignore", ".nowignore",
+	".*.swp", ".DS_Store", ".wafpickle-*", ".lock-wscript", ".env", ".env.build",
+}
+
+// nowIgnoreFile and gitIgnoreFile are the names of the ignore files read from the root of a deployed directory.
+const (
+	nowIgnoreFile = ".nowignore"
+	gitIgnoreFile = ".gitignore"
+)
+
+// DeployOptions changes how Deploy creates a deployment.
+type DeployOptions struct {
+	// Name is the name of the deployment, if empty the name of the directory is used.
+	Name string
+	// Target is the environment of the deployment, production or staging. If empty a preview deployment is created.
+	Target string
+	Meta   map[string]string
+	// Concurrency is the most files uploaded at once, zero uses the same default as BulkOptions.Concurrency.
+	Concurrency int
+	// GitIgnore also applies the patterns of the .gitignore file of the directory, after those of its .nowignore
+	// file.
+	GitIgnore bool
+	// Ignore is more patterns of files not to deploy, applied after those of the ignore files.
+	Ignore []string
+}
+
+// DeployFile is a file of the manifest a deployment is created from.
+type DeployFile struct {
+	// File is the path of the file relative to the deployed directory, separated by forward slashes.
+	File string `json:"file"`
+	// Sha is the hex encoded SHA1 digest of the contents of the file, files are uploaded and stored by their digest.
+	Sha  string `json:"sha"`
+	Size int64  `json:"size"`
+	// Mode is the unix mode of the file, including its type.
+	Mode uint32 `json:"mode"`
+
+	// path is where the file is read from.
+	path string
+}
+
+// Deploy will create a deployment of the files in the directory, see DeployFiles for the files that are included. The
+// deployment is created from the manifest of digests, any files the API reports missing are uploaded in parallel before
+// the deployment is created again. The deployment is returned as soon as it is created, it is still being built.
+func (c Client) Deploy(ctx context.Context, dir string, opts DeployOptions) (*Deployment, error) {
+	files, err := DeployFiles(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Name == "" {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		opts.Name = filepath.Base(abs)
+	}
+
+	deployment, err := c.createDeployment(ctx, files, opts)
+	var missingFilesError *MissingFilesError
+	if !errors.As(err, &missingFilesError) {
+		return deployment, err
+	}
+	if err := c.uploadDeployFiles(ctx, files, missingFilesError.Missing, opts); err != nil {
+		return nil, err
+	}
+	return c.createDeployment(ctx, files, opts)
+}
+
+// UploadFile will upload the contents of a file so it can be included in a deployment, the hex encoded SHA1 digest the
+// file is stored by is returned. Uploading a file that already exists has no effect.
+func (c Client) UploadFile(data []byte) (string, error) {
+	return c.UploadFileContext(context.Background(), data)
+}
+
+// UploadFileContext is the same as UploadFile but the request is bound to the given context.
+func (c Client) UploadFileContext(ctx context.Context, data []byte) (string, error) {
+	digest := sha1.Sum(data)
+	sha := hex.EncodeToString(digest[:])
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	header.Set("x-now-digest", sha)
+	header.Set("x-now-size", strconv.Itoa(len(data)))
+	// files are stored by their digest so sending one twice is safe
+	err := c.do(ContextWithIdempotent(ctx), request{
+		method:   http.MethodPost,
+		endpoint: "v2/now/files",
+		raw:      data,
+		header:   header,
+	})
+	if err != nil {
+		return "", err
+	}
+	return sha, nil
+}
+
+// createDeployment will create a deployment from the manifest, a *MissingFilesError is returned if any of the files
+// haven't been uploaded.
+func (c Client) createDeployment(ctx context.Context, files []DeployFile, opts DeployOptions) (*Deployment, error) {
+	deployment := Deployment{}
+	err := c.do(ctx, request{
+		method:   http.MethodPost,
+		endpoint: "v12/now/deployments",
+		body: struct {
+			Name   string            `json:"name"`
+			Files  []DeployFile      `json:"files"`
+			Target string            `json:"target,omitempty"`
+			Meta   map[string]string `json:"meta,omitempty"`
+		}{opts.Name, files, opts.Target, opts.Meta},
+		result:    &deployment,
+		wrapError: newMissingFilesError,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// uploadDeployFiles will upload the files of the manifest with the missing digests.
+func (c Client) uploadDeployFiles(ctx context.Context, files []DeployFile, missing []string, opts DeployOptions) error {
+	bySha := make(map[string]DeployFile)
+	for _, file := range files {
+		bySha[file.Sha] = file
+	}
+	// files with the same contents are only uploaded once
+	seen := make(map[string]bool)
+	var shas []string
+	for _, sha := range missing {
+		if _, ok := bySha[sha]; !ok {
+			return fmt.Errorf("file %s reported missing is not in the manifest", sha)
+		}
+		if !seen[sha] {
+			seen[sha] = true
+			shas = append(shas, sha)
+		}
+	}
+
+	results := c.bulk(ctx, len(shas), BulkOptions{Concurrency: opts.Concurrency}, func(ctx context.Context, i int) (string, error) {
+		file := bySha[shas[i]]
+		data, err := ioutil.ReadFile(file.path)
+		if err != nil {
+			return "", err
+		}
+		sha, err := c.UploadFileContext(ctx, data)
+		if err != nil {
+			return "", fmt.Errorf("uploading %s: %w", file.File, err)
+		}
+		if sha != file.Sha {
+			return "", fmt.Errorf("%s changed while deploying", file.File)
+		}
+		return sha, nil
+	})
+	return bulkError(results)
+}
+
+// DeployFiles will return the manifest Deploy would create a deployment of the directory from, in lexical order, without
+// uploading anything. Only regular files are included, symbolic links are skipped. Files matching DefaultIgnore, the
+// patterns of the .nowignore file at the root of the directory, the .gitignore file if DeployOptions.GitIgnore is set
+// or DeployOptions.Ignore are left out, with the semantics of gitignore.
+func DeployFiles(dir string, opts DeployOptions) ([]DeployFile, error) {
+	matcher, err := deployIgnore(dir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []DeployFile
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if rel == "." {
+			return nil
+		}
+		if matcher.Match(rel, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		sha, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+		files = append(files, DeployFile{
+			File: rel,
+			Sha:  sha,
+			Size: info.Size(),
+			Mode: unixFileMode | uint32(info.Mode().Perm()),
+			path: path,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// deployIgnore returns the matcher of the files of the directory that aren't deployed.
+func deployIgnore(dir string, opts DeployOptions) (*ignore.Matcher, error) {
+	matcher, err := ignore.New(DefaultIgnore...)
+	if err != nil {
+		return nil, err
+	}
+	ignoreFiles := []string{nowIgnoreFile}
+	if opts.GitIgnore {
+		ignoreFiles = append(ignoreFiles, gitIgnoreFile)
+	}
+	for _, name := range ignoreFiles {
+		if err := matcher.AddFile(filepath.Join(dir, name)); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+	if err := matcher.Add(opts.Ignore...); err != nil {
+		return nil, err
+	}
+	return matcher, nil
+}
+
+// unixFileMode is the type bits of the unix mode of a regular file.
+const unixFileMode = 0100000
+
+// hashFile returns the hex encoded SHA1 digest of the contents of the file.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha1.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,205 @@
+package zeit
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFiles creates the files, keyed by their slash separated path, in a new temporary directory.
+func writeFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "zeit-deploy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, contents := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestClient_Deploy(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	dir := writeFiles(t, map[string]string{
+		"index.html":      "<h1>hello</h1>",
+		"css/site.css":    "h1 { color: red }",
+		"css/print.css":   "h1 { color: black }",
+		"js/app.js":       "console.log('hello')",
+		"js/app.copy.js":  "console.log('hello')",
+		"img/favicon.ico": "icon",
+	})
+	defer os.RemoveAll(dir)
+	a.Nil(os.Symlink(filepath.Join(dir, "index.html"), filepath.Join(dir, "link.html")))
+	// the icon has been uploaded by an earlier deployment
+	server.AddFile("", []byte("icon"))
+
+	requests := server.Requests()
+	deployment, err := client.Deploy(context.Background(), dir, DeployOptions{
+		Name:   "site",
+		Target: "production",
+		Meta:   map[string]string{"commit": "abc123"},
+	})
+	a.Nil(err, "Error should be nil")
+	a.Equal("site", deployment.Name)
+	a.Equal(DeploymentStateInitializing, deployment.State)
+	a.Equal("production", deployment.Target)
+	a.Equal("abc123", deployment.Meta["commit"])
+	a.NotEmpty(deployment.Id)
+	// the first create reports the missing files, the two copies of app.js are uploaded once and the icon not at all
+	a.Equal(6, server.Requests()-requests, "only missing files should be uploaded")
+
+	for _, contents := range []string{"<h1>hello</h1>", "h1 { color: red }", "console.log('hello')"} {
+		digest := sha1.Sum([]byte(contents))
+		stored, ok := server.File("", hex.EncodeToString(digest[:]))
+		a.True(ok, "%q should have been uploaded", contents)
+		a.Equal(contents, string(stored))
+	}
+
+	files, err := client.ListDeploymentFiles(deployment.Id)
+	a.Nil(err, "Error should be nil")
+	names := make([]string, 0)
+	for _, file := range files {
+		names = append(names, file.Name)
+	}
+	a.ElementsMatch([]string{"css", "img", "index.html", "js"}, names, "symbolic links should be skipped")
+
+	// every file has been uploaded so the deployment is created with a single request
+	requests = server.Requests()
+	_, err = client.Deploy(context.Background(), dir, DeployOptions{})
+	a.Nil(err, "Error should be nil")
+	a.Equal(1, server.Requests()-requests)
+	deployments := server.Deployments("")
+	a.Equal(filepath.Base(dir), deployments[0].Name, "name should default to the directory")
+}
+
+func TestClient_DeployError(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	dir := writeFiles(t, map[string]string{"index.html": "<h1>hello</h1>"})
+	defer os.RemoveAll(dir)
+
+	server.Handle(http.MethodPost, "/v2/now/files", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": {"code": "forbidden", "message": "Not allowed"}}`))
+	})
+	_, err := client.Deploy(context.Background(), dir, DeployOptions{})
+	a.True(errors.Is(err, ErrForbidden), "upload errors should be returned")
+	var bulkError *BulkError
+	a.True(errors.As(err, &bulkError))
+
+	server.Handle(http.MethodPost, "/v12/now/deployments", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error": {"code": "bad_request", "message": "Invalid name"}}`))
+	})
+	_, err = client.Deploy(context.Background(), dir, DeployOptions{})
+	var missingFilesError *MissingFilesError
+	a.False(errors.As(err, &missingFilesError), "only errors listing missing files should be wrapped")
+	var apiError *APIError
+	a.True(errors.As(err, &apiError))
+	a.Equal("bad_request", apiError.Code)
+
+	_, err = client.Deploy(context.Background(), filepath.Join(dir, "missing"), DeployOptions{})
+	a.True(os.IsNotExist(err), "walking a missing directory should fail")
+}
+
+func TestDeployFiles(t *testing.T) {
+	a := assert.New(t)
+	dir := writeFiles(t, map[string]string{
+		"index.html":               "<h1>hello</h1>",
+		".env":                     "SECRET=1",
+		".git/HEAD":                "ref: refs/heads/master",
+		"node_modules/x/index.js":  "module.exports = 1",
+		"src/app.js":               "console.log('hello')",
+		"src/app.js.map":           "{}",
+		"src/vendor/lib.js":        "lib",
+		"logs/debug.log":           "debug",
+		"logs/important.log":       "important",
+		"build/out.txt":            "out",
+		".well-known/security.txt": "contact",
+		".nowignore":               "# not deployed\n**/*.map\nlogs/*\n!logs/important.log\n",
+		".gitignore":               "/build\n",
+	})
+	defer os.RemoveAll(dir)
+
+	paths := func(files []DeployFile) []string {
+		paths := make([]string, 0, len(files))
+		for _, file := range files {
+			paths = append(paths, file.File)
+		}
+		return paths
+	}
+
+	a.Nil(os.Chmod(filepath.Join(dir, "index.html"), 0644))
+	files, err := DeployFiles(dir, DeployOptions{})
+	a.Nil(err, "Error should be nil")
+	a.Equal([]string{
+		".well-known/security.txt",
+		"build/out.txt",
+		"index.html",
+		"logs/important.log",
+		"src/app.js",
+		"src/vendor/lib.js",
+	}, paths(files))
+	a.Equal(int64(len("<h1>hello</h1>")), files[2].Size)
+	a.Equal(uint32(0100644), files[2].Mode)
+
+	files, err = DeployFiles(dir, DeployOptions{GitIgnore: true, Ignore: []string{"vendor/", "!.env"}})
+	a.Nil(err, "Error should be nil")
+	a.Equal([]string{
+		".env",
+		".well-known/security.txt",
+		"index.html",
+		"logs/important.log",
+		"src/app.js",
+	}, paths(files), "default patterns can be negated")
+
+	_, err = DeployFiles(dir, DeployOptions{Ignore: []string{"[z-a]"}})
+	a.Error(err, "invalid patterns should be rejected")
+}
+
+func TestClient_UploadFile(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	var header http.Header
+	var body []byte
+	server.Handle(http.MethodPost, "/v2/now/files", func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header
+		body, _ = ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{}`))
+	})
+
+	sha, err := client.UploadFile([]byte("hello"))
+	a.Nil(err, "Error should be nil")
+	a.Equal("aaf4c61ddcc5e8a2dabede0f3b482cd9aea9434d", sha)
+	a.Equal("hello", string(body), "body should be sent unchanged")
+	a.Equal("application/octet-stream", header.Get("Content-Type"))
+	a.Equal(sha, header.Get("x-now-digest"))
+	a.Equal("5", header.Get("x-now-size"))
+	a.Equal("Bearer "+server.Token, header.Get("Authorization"))
+}
@@ -0,0 +1,185 @@
+package zeit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// DeploymentState is the state of a deployment as it is built and started.
+type DeploymentState string
+
+const (
+	DeploymentStateInitializing DeploymentState = "INITIALIZING"
+	DeploymentStateAnalyzing    DeploymentState = "ANALYZING"
+	DeploymentStateBuilding     DeploymentState = "BUILDING"
+	DeploymentStateDeploying    DeploymentState = "DEPLOYING"
+	DeploymentStateReady        DeploymentState = "READY"
+	DeploymentStateError        DeploymentState = "ERROR"
+	DeploymentStateCanceled     DeploymentState = "CANCELED"
+)
+
+type Deployment struct {
+	Id        string            `json:"id"`
+	Name      string            `json:"name"`
+	Url       string            `json:"url"`
+	State     DeploymentState   `json:"state"`
+	Target    string            `json:"target,omitempty"`
+	ProjectId string            `json:"projectId,omitempty"`
+	OwnerId   string            `json:"ownerId,omitempty"`
+	Meta      map[string]string `json:"meta,omitempty"`
+	Regions   []string          `json:"regions,omitempty"`
+	Alias     []string          `json:"alias,omitempty"`
+	Created   *Time             `json:"created"`
+	// ErrorCode and ErrorMessage say why the deployment failed when its state is ERROR.
+	ErrorCode    string `json:"errorCode,omitempty"`
+	ErrorMessage string `json:"errorMessage,omitempty"`
+}
+
+// UnmarshalJSON reads a deployment from either of the formats used by the API, the list endpoint names the id, state
+// and creation time uid, state and created while the deployment endpoint names them id, readyState and createdAt.
+func (d *Deployment) UnmarshalJSON(data []byte) error {
+	type deployment Deployment
+	aliases := struct {
+		*deployment
+		Uid        string          `json:"uid"`
+		ReadyState DeploymentState `json:"readyState"`
+		CreatedAt  *Time           `json:"createdAt"`
+	}{deployment: (*deployment)(d)}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return err
+	}
+	if d.Id == "" {
+		d.Id = aliases.Uid
+	}
+	if d.State == "" {
+		d.State = aliases.ReadyState
+	}
+	if d.Created == nil {
+		d.Created = aliases.CreatedAt
+	}
+	return nil
+}
+
+// DeploymentFile is a file or directory of the file tree of a deployment.
+type DeploymentFile struct {
+	Name string `json:"name"`
+	// Type is file, directory, symlink or lambda.
+	Type     string           `json:"type"`
+	Uid      string           `json:"uid,omitempty"`
+	Mode     int              `json:"mode"`
+	Children []DeploymentFile `json:"children,omitempty"`
+}
+
+// ListDeployments will return the deployments of the user, newest first. Every page of deployments is requested, use
+// IterateDeployments to read them lazily instead. Use WithProject and WithDeploymentState to filter the deployments
+// and WithSince and WithUntil to only list deployments created in a window of time.
+func (c Client) ListDeployments(opts ...ListOption) ([]Deployment, error) {
+	return c.ListDeploymentsContext(context.Background(), opts...)
+}
+
+// ListDeploymentsContext is the same as ListDeployments but the requests are bound to the given context.
+func (c Client) ListDeploymentsContext(ctx context.Context, opts ...ListOption) ([]Deployment, error) {
+	deployments := make([]Deployment, 0)
+	it := c.IterateDeployments(opts...)
+	for it.Next(ctx) {
+		deployments = append(deployments, it.Value())
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return deployments, nil
+}
+
+// GetDeployment will return the deployment with the id.
+func (c Client) GetDeployment(id string) (*Deployment, error) {
+	return c.GetDeploymentContext(context.Background(), id)
+}
+
+// GetDeploymentContext is the same as GetDeployment but the request is bound to the given context.
+func (c Client) GetDeploymentContext(ctx context.Context, id string) (*Deployment, error) {
+	deployment := Deployment{}
+	err := c.do(ctx, request{
+		method:   http.MethodGet,
+		endpoint: fmt.Sprintf("v9/now/deployments/%s", id),
+		result:   &deployment,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &deployment, nil
+}
+
+// DeleteDeployment will delete the deployment with the id.
+func (c Client) DeleteDeployment(id string) error {
+	return c.DeleteDeploymentContext(context.Background(), id)
+}
+
+// DeleteDeploymentContext is the same as DeleteDeployment but the request is bound to the given context.
+func (c Client) DeleteDeploymentContext(ctx context.Context, id string) error {
+	return c.do(ctx, request{
+		method:   http.MethodDelete,
+		endpoint: fmt.Sprintf("v9/now/deployments/%s", id),
+	})
+}
+
+// ListDeploymentFiles will return the file tree of the deployment with the id.
+func (c Client) ListDeploymentFiles(id string) ([]DeploymentFile, error) {
+	return c.ListDeploymentFilesContext(context.Background(), id)
+}
+
+// ListDeploymentFilesContext is the same as ListDeploymentFiles but the request is bound to the given context.
+func (c Client) ListDeploymentFilesContext(ctx context.Context, id string) ([]DeploymentFile, error) {
+	files := make([]DeploymentFile, 0)
+	err := c.do(ctx, request{
+		method:   http.MethodGet,
+		endpoint: fmt.Sprintf("v5/now/deployments/%s/files", id),
+		result:   &files,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// DeploymentIterator lazily lists deployments, requesting a new page from the API only once every deployment on the
+// previous page has been read.
+type DeploymentIterator struct {
+	pager *pager
+	page  []Deployment
+	index int
+}
+
+// IterateDeployments will return an iterator over the deployments of the user, newest first.
+func (c Client) IterateDeployments(opts ...ListOption) *DeploymentIterator {
+	return &DeploymentIterator{pager: newPager(c, "v5/now/deployments", opts), index: -1}
+}
+
+// Next advances the iterator to the next deployment, it returns false when there are no more deployments or an error
+// occurred.
+func (it *DeploymentIterator) Next(ctx context.Context) bool {
+	for it.index+1 >= len(it.page) {
+		it.page, it.index = nil, -1
+		pagination := Pagination{}
+		result := &struct {
+			Deployments *[]Deployment `json:"deployments"`
+			Pagination  *Pagination   `json:"pagination"`
+		}{&it.page, &pagination}
+		if !it.pager.fetch(ctx, result, &pagination) {
+			return false
+		}
+	}
+	it.index++
+	return true
+}
+
+// Value returns the current deployment.
+func (it *DeploymentIterator) Value() Deployment {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *DeploymentIterator) Err() error {
+	return it.pager.err
+}
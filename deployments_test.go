@@ -0,0 +1,115 @@
+package zeit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_ListDeployments(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.SetPageSize(2)
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	first := server.AddDeployment("", zeittest.Deployment{Name: "web", ProjectId: "prj_web"})
+	server.AddDeployment("", zeittest.Deployment{Name: "api", ProjectId: "prj_api", State: "ERROR"})
+	server.AddDeployment("", zeittest.Deployment{Name: "web", ProjectId: "prj_web", State: "BUILDING"})
+	last := server.AddDeployment("", zeittest.Deployment{Name: "web", ProjectId: "prj_web"})
+
+	deployments, err := client.ListDeployments()
+	a.Nil(err, "Error should be nil")
+	a.Len(deployments, 4, "every page should be listed")
+	a.Equal(last.Id, deployments[0].Id, "newest deployment should be first")
+	a.Equal(DeploymentStateReady, deployments[0].State)
+	a.Equal(last.Url, deployments[0].Url)
+	a.Equal(last.Created, deployments[0].Created.UnixNano()/int64(time.Millisecond))
+
+	deployments, err = client.ListDeployments(WithProject("prj_web"), WithDeploymentState(DeploymentStateReady))
+	a.Nil(err, "Error should be nil")
+	a.Len(deployments, 2)
+	for _, deployment := range deployments {
+		a.Equal("web", deployment.Name)
+		a.Equal(DeploymentStateReady, deployment.State)
+	}
+
+	deployments, err = client.ListDeployments(WithDeploymentState(DeploymentStateBuilding, DeploymentStateError))
+	a.Nil(err, "Error should be nil")
+	a.Len(deployments, 2)
+
+	deployments, err = client.ListDeployments(WithUntil(time.Unix(0, last.Created*int64(time.Millisecond))),
+		WithSince(time.Unix(0, first.Created*int64(time.Millisecond))))
+	a.Nil(err, "Error should be nil")
+	a.Len(deployments, 2, "only deployments in the window should be listed")
+}
+
+func TestClient_GetDeployment(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	added := server.AddDeployment("", zeittest.Deployment{
+		Name:         "web",
+		State:        "ERROR",
+		ErrorCode:    "build_failed",
+		ErrorMessage: "The build failed",
+		Meta:         map[string]string{"githubCommitRef": "master"},
+	})
+
+	deployment, err := client.GetDeployment(added.Id)
+	a.Nil(err, "Error should be nil")
+	a.Equal(added.Id, deployment.Id)
+	a.Equal(DeploymentStateError, deployment.State, "readyState should be read as the state")
+	a.Equal("build_failed", deployment.ErrorCode)
+	a.Equal("The build failed", deployment.ErrorMessage)
+	a.Equal("master", deployment.Meta["githubCommitRef"])
+	a.NotNil(deployment.Created, "createdAt should be read as the creation time")
+
+	_, err = client.GetDeployment("dpl_missing")
+	a.True(errors.Is(err, ErrNotFound), "missing deployments should not be found")
+}
+
+func TestClient_DeleteDeployment(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	added := server.AddDeployment("", zeittest.Deployment{})
+	a.Nil(client.DeleteDeployment(added.Id))
+	a.Empty(server.Deployments(""))
+
+	err := client.DeleteDeployment(added.Id)
+	a.True(errors.Is(err, ErrNotFound), "deleted deployments should not be found")
+}
+
+func TestClient_ListDeploymentFiles(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	added := server.AddDeployment("", zeittest.Deployment{Files: []zeittest.File{
+		{Name: "src", Type: "directory", Mode: 16877, Children: []zeittest.File{
+			{Name: "index.js", Type: "file", Uid: "2d4a", Mode: 33188},
+		}},
+		{Name: "package.json", Type: "file", Uid: "9b1c", Mode: 33188},
+	}})
+
+	files, err := client.ListDeploymentFiles(added.Id)
+	a.Nil(err, "Error should be nil")
+	a.Equal([]DeploymentFile{
+		{Name: "src", Type: "directory", Mode: 16877, Children: []DeploymentFile{
+			{Name: "index.js", Type: "file", Uid: "2d4a", Mode: 33188},
+		}},
+		{Name: "package.json", Type: "file", Uid: "9b1c", Mode: 33188},
+	}, files)
+
+	_, err = client.ListDeploymentFiles("dpl_missing")
+	a.True(errors.Is(err, ErrNotFound), "missing deployments should not be found")
+}
@@ -1,41 +1,41 @@
 package zeit
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 )
 
-func (c Client) ListDNSRecords(domain string) ([]Record, error) {
-	endpoint := fmt.Sprintf("v2/domains/%s/records", domain)
-
-	resp, err := c.makeAndDoRequest(http.MethodGet, endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-	defer closeResponseBody(resp)
+// ListDNSRecords will return every DNS record of the domain. Every page of records is requested, use IterateDNSRecords
+// to read them lazily instead.
+func (c Client) ListDNSRecords(domain string, opts ...ListOption) ([]Record, error) {
+	return c.ListDNSRecordsContext(context.Background(), domain, opts...)
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
+// ListDNSRecordsContext is the same as ListDNSRecords but the requests are bound to the given context.
+func (c Client) ListDNSRecordsContext(ctx context.Context, domain string, opts ...ListOption) ([]Record, error) {
+	records := make([]Record, 0)
+	it := c.IterateDNSRecords(domain, opts...)
+	for it.Next(ctx) {
+		records = append(records, it.Value())
 	}
-
-	var records []Record
-
-	err = json.NewDecoder(resp.Body).Decode(&struct {
-		Records *[]Record
-	}{&records})
-	if err != nil {
+	if err := it.Err(); err != nil {
 		return nil, err
 	}
-
 	return records, nil
 }
 
+// CreateDNSRecord will create a new DNS record for the domain and return its id. The record is validated with Validate
+// before it is sent, the existing records of the domain aren't checked so a CNAME conflicting with them is only
+// rejected by the API. Use ValidateRecords with the listed records to check for conflicts first.
 func (c Client) CreateDNSRecord(domain string, record *Record) (string, error) {
+	return c.CreateDNSRecordContext(context.Background(), domain, record)
+}
+
+// CreateDNSRecordContext is the same as CreateDNSRecord but the request is bound to the given context.
+func (c Client) CreateDNSRecordContext(ctx context.Context, domain string, record *Record) (string, error) {
 	if record == nil {
 		return "", errors.New(ErrorNilRecord)
 	}
@@ -44,73 +44,100 @@ func (c Client) CreateDNSRecord(domain string, record *Record) (string, error) {
 		return "", errors.New(ErrorOrigin)
 	}
 
-	parameters := struct {
-		Name       string `json:"name"`
-		RecordType string `json:"type"`
-		Value      string `json:"value"`
-	}{record.Name, record.Type, strings.TrimSuffix(record.GetValue(), ".")}
-
-	body, err := json.Marshal(parameters)
-	if err != nil {
+	if err := record.Validate(); err != nil {
 		return "", err
 	}
 
-	endpoint := fmt.Sprintf("v2/domains/%s/records", domain)
-	resp, err := c.makeAndDoRequest(http.MethodPost, endpoint, bytes.NewBuffer(body))
-	defer closeResponseBody(resp)
+	var uid string
+	err := c.do(ctx, request{
+		method:   http.MethodPost,
+		endpoint: fmt.Sprintf("v2/domains/%s/records", domain),
+		body: struct {
+			Name       string `json:"name"`
+			RecordType string `json:"type"`
+			Value      string `json:"value"`
+			TTL        int    `json:"ttl,omitempty"`
+		}{record.Name, record.Type, strings.TrimSuffix(record.GetValue(), "."), record.TTL},
+		result: &struct {
+			Uid *string `json:"uid"`
+		}{&uid},
+	})
 	if err != nil {
 		return "", err
 	}
+	return uid, nil
+}
 
-	if resp.StatusCode == http.StatusBadRequest {
-		requestError := BasicError{}
-		err = json.NewDecoder(resp.Body).Decode(&struct {
-			Error BasicError
-		}{requestError})
-		if err != nil {
-			return "", err
-		}
-		return "", requestError
-	}
+func (c Client) RemoveDNSRecord(domain, recId string) error {
+	return c.RemoveDNSRecordContext(context.Background(), domain, recId)
+}
 
-	if resp.StatusCode == http.StatusConflict {
-		conflictError := ConflictError{}
-		err = json.NewDecoder(resp.Body).Decode(&struct {
-			Error ConflictError
-		}{conflictError})
-		if err != nil {
-			return "", err
-		}
-		log.Println(resp.Status, record.Name, record.Type, record.GetValue())
-		return "", conflictError
-	}
+// RemoveDNSRecordContext is the same as RemoveDNSRecord but the request is bound to the given context.
+func (c Client) RemoveDNSRecordContext(ctx context.Context, domain, recId string) error {
+	return c.do(ctx, request{
+		method:   http.MethodDelete,
+		endpoint: fmt.Sprintf("v2/domains/%s/records/%s", domain, recId),
+	})
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(resp.Status)
-	}
+// UpdateDNSRecord will change the name, type and value of an existing record in place, the record keeps its id. The
+// record is validated with Validate before it is sent.
+func (c Client) UpdateDNSRecord(domain, recId string, record *Record) error {
+	return c.UpdateDNSRecordContext(context.Background(), domain, recId, record)
+}
 
-	var uid string
-	err = json.NewDecoder(resp.Body).Decode(&struct {
-		Uid *string
-	}{&uid})
+// UpdateDNSRecordContext is the same as UpdateDNSRecord but the request is bound to the given context.
+func (c Client) UpdateDNSRecordContext(ctx context.Context, domain, recId string, record *Record) error {
+	if record == nil {
+		return errors.New(ErrorNilRecord)
+	}
 
-	if err != nil {
-		return "", err
+	if err := record.Validate(); err != nil {
+		return err
 	}
 
-	return uid, nil
+	return c.do(ctx, request{
+		method:   http.MethodPatch,
+		endpoint: fmt.Sprintf("v2/domains/%s/records/%s", domain, recId),
+		body: struct {
+			Name       string `json:"name"`
+			RecordType string `json:"type"`
+			Value      string `json:"value"`
+			TTL        int    `json:"ttl,omitempty"`
+		}{record.Name, record.Type, strings.TrimSuffix(record.GetValue(), "."), record.TTL},
+	})
 }
 
-func (c Client) RemoveDNSRecord(domain, recId string) error {
-	endpoint := fmt.Sprintf("v2/domains/%s/records/%s", domain, recId)
-	resp, err := c.makeAndDoRequest(http.MethodDelete, endpoint, nil)
+// ReplaceDNSRecord will replace an existing record with a new one and return the id of the record. The record is
+// updated in place with UpdateDNSRecord where the API supports it. Otherwise the new record is created before the old
+// one is removed so the name keeps resolving, if the old record can't be removed the new one is removed again and the
+// original error is returned.
+func (c Client) ReplaceDNSRecord(domain, recId string, record *Record) (string, error) {
+	return c.ReplaceDNSRecordContext(context.Background(), domain, recId, record)
+}
+
+// ReplaceDNSRecordContext is the same as ReplaceDNSRecord but the requests are bound to the given context.
+func (c Client) ReplaceDNSRecordContext(ctx context.Context, domain, recId string, record *Record) (string, error) {
+	err := c.UpdateDNSRecordContext(ctx, domain, recId, record)
+	if err == nil {
+		return recId, nil
+	}
+	var apiError *APIError
+	if !errors.As(err, &apiError) ||
+		(apiError.StatusCode != http.StatusMethodNotAllowed && apiError.StatusCode != http.StatusNotImplemented) {
+		return "", err
+	}
 
-	defer closeResponseBody(resp)
+	uid, err := c.CreateDNSRecordContext(ctx, domain, record)
 	if err != nil {
-		return err
+		return "", err
 	}
-	if resp.StatusCode != http.StatusOK {
-		return errors.New(resp.Status)
+	if err := c.RemoveDNSRecordContext(ctx, domain, recId); err != nil {
+		if rollbackErr := c.RemoveDNSRecordContext(ctx, domain, uid); rollbackErr != nil {
+			return "", fmt.Errorf("removing record %s failed and so did removing its replacement %s (%v): %w",
+				recId, uid, rollbackErr, err)
+		}
+		return "", err
 	}
-	return nil
+	return uid, nil
 }
@@ -0,0 +1,183 @@
+// Package dns01 answers ACME DNS-01 challenges with ZEIT DNS. Provider has the Present, CleanUp and Timeout methods
+// expected by the DNS provider interfaces of ACME libraries such as lego, so it can be passed to them directly.
+//
+//	provider := dns01.NewProvider(zeit.NewClient(token), dns01.Config{})
+//	err := client.Challenge.SetDNS01Provider(provider)
+package dns01
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kochie/zeit-api-go"
+)
+
+// Defaults used by NewProvider when Config leaves them unset.
+const (
+	DefaultTTL                = 60
+	DefaultPropagationTimeout = 2 * time.Minute
+	DefaultPollingInterval    = 2 * time.Second
+)
+
+// challengeLabel is the label the DNS-01 challenge of a domain is answered at.
+const challengeLabel = "_acme-challenge"
+
+// Client is the part of *zeit.Client used by the Provider.
+type Client interface {
+	ListAllDomainsContext(ctx context.Context, opts ...zeit.ListOption) ([]zeit.Domain, error)
+	CreateDNSRecordContext(ctx context.Context, domain string, record *zeit.Record) (string, error)
+	RemoveDNSRecordContext(ctx context.Context, domain, recId string) error
+}
+
+// Config changes how a Provider answers challenges.
+type Config struct {
+	// Zone is the ZEIT domain the challenge records are created in. If empty, the longest of the account's domains
+	// that the challenged domain is part of is used.
+	Zone string
+	// TTL of the challenge records, zero uses DefaultTTL.
+	TTL int
+	// PropagationTimeout is how long the ACME client should wait for the record to be visible, and PollingInterval how
+	// often it should check. Zero uses DefaultPropagationTimeout and DefaultPollingInterval.
+	PropagationTimeout time.Duration
+	PollingInterval    time.Duration
+}
+
+// Provider creates and removes the TXT records that answer DNS-01 challenges. It is safe for concurrent use.
+type Provider struct {
+	client Client
+	config Config
+
+	mutex   sync.Mutex
+	records map[string]record
+}
+
+// record is a challenge record created by the provider.
+type record struct {
+	zone string
+	id   string
+}
+
+// NewProvider will create a provider that answers challenges with the client.
+func NewProvider(client Client, config Config) *Provider {
+	if config.TTL <= 0 {
+		config.TTL = DefaultTTL
+	}
+	if config.PropagationTimeout <= 0 {
+		config.PropagationTimeout = DefaultPropagationTimeout
+	}
+	if config.PollingInterval <= 0 {
+		config.PollingInterval = DefaultPollingInterval
+	}
+	return &Provider{client: client, config: config, records: make(map[string]record)}
+}
+
+// ChallengeName returns the fully qualified name of the TXT record that answers the challenge of the domain, a
+// wildcard domain is answered at the same name as its base domain.
+func ChallengeName(domain string) string {
+	domain = strings.TrimSuffix(strings.TrimPrefix(domain, "*."), ".")
+	return challengeLabel + "." + domain
+}
+
+// ChallengeValue returns the value of the TXT record that answers a challenge, the unpadded base64url encoding of the
+// SHA-256 digest of the key authorization.
+func ChallengeValue(keyAuth string) string {
+	digest := sha256.Sum256([]byte(keyAuth))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// Present will create the TXT record that answers the challenge of the domain.
+func (p *Provider) Present(domain, token, keyAuth string) error {
+	return p.PresentContext(context.Background(), domain, token, keyAuth)
+}
+
+// PresentContext is the same as Present but the requests are bound to the given context.
+func (p *Provider) PresentContext(ctx context.Context, domain, token, keyAuth string) error {
+	name := ChallengeName(domain)
+	value := ChallengeValue(keyAuth)
+	zone, err := p.zone(ctx, name)
+	if err != nil {
+		return err
+	}
+	id, err := p.client.CreateDNSRecordContext(ctx, zone, &zeit.Record{
+		Name:  name[:len(name)-len(zone)-1],
+		Type:  zeit.RecordTypeTXT,
+		Value: value,
+		TTL:   p.config.TTL,
+	})
+	if err != nil {
+		return fmt.Errorf("dns01: creating %s: %w", name, err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.records[name+" "+value] = record{zone: zone, id: id}
+	return nil
+}
+
+// CleanUp will remove the TXT record created by Present for the challenge. Challenges that weren't presented by the
+// provider are ignored.
+func (p *Provider) CleanUp(domain, token, keyAuth string) error {
+	return p.CleanUpContext(context.Background(), domain, token, keyAuth)
+}
+
+// CleanUpContext is the same as CleanUp but the request is bound to the given context.
+func (p *Provider) CleanUpContext(ctx context.Context, domain, token, keyAuth string) error {
+	name := ChallengeName(domain)
+	key := name + " " + ChallengeValue(keyAuth)
+
+	p.mutex.Lock()
+	r, ok := p.records[key]
+	p.mutex.Unlock()
+	if !ok {
+		return nil
+	}
+	if err := p.client.RemoveDNSRecordContext(ctx, r.zone, r.id); err != nil {
+		return fmt.Errorf("dns01: removing %s: %w", name, err)
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	delete(p.records, key)
+	return nil
+}
+
+// Timeout returns how long the ACME client should wait for a record to propagate and how often it should check.
+func (p *Provider) Timeout() (timeout, interval time.Duration) {
+	return p.config.PropagationTimeout, p.config.PollingInterval
+}
+
+// zone returns the ZEIT domain the record with the fully qualified name belongs to.
+func (p *Provider) zone(ctx context.Context, name string) (string, error) {
+	if p.config.Zone != "" {
+		zone := strings.TrimSuffix(p.config.Zone, ".")
+		if !inZone(name, zone) {
+			return "", fmt.Errorf("dns01: %s is not part of %s", name, zone)
+		}
+		return zone, nil
+	}
+
+	domains, err := p.client.ListAllDomainsContext(ctx)
+	if err != nil {
+		return "", err
+	}
+	zone := ""
+	for _, domain := range domains {
+		if inZone(name, domain.Name) && len(domain.Name) > len(zone) {
+			zone = domain.Name
+		}
+	}
+	if zone == "" {
+		return "", fmt.Errorf("dns01: no domain found for %s", name)
+	}
+	return zone, nil
+}
+
+// inZone reports whether the fully qualified name is part of the zone.
+func inZone(name, zone string) bool {
+	return strings.HasSuffix(strings.ToLower(name), "."+strings.ToLower(zone))
+}
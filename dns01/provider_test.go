@@ -0,0 +1,82 @@
+package dns01_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kochie/zeit-api-go"
+	"github.com/kochie/zeit-api-go/dns01"
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChallengeValue(t *testing.T) {
+	a := assert.New(t)
+	// computed with sha256sum | xxd -r -p | base64 -w0 | tr '+/' '-_' | tr -d '='
+	keyAuth := "evaGxfADs6pSRb2LAv9IZf17Dt3juxGJ-PCt92wr-oA.nP1qzpXGymHBrUEepNY9HCsQk7K8KhOypzEt62jcerQ"
+	a.Equal("NGwKoXBgCT8JhEa0bK7AwfSqHyu_ZWeugV07fLGIVq0", dns01.ChallengeValue(keyAuth))
+	a.Equal("_acme-challenge.example.com", dns01.ChallengeName("*.example.com."))
+}
+
+func TestProvider(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	server.AddDomain("", "example.net")
+	client := zeit.NewClient(server.Token, zeit.WithBaseURL(server.URL))
+
+	provider := dns01.NewProvider(client, dns01.Config{})
+	a.Nil(provider.Present("www.example.com", "token", "key-auth"))
+	a.Nil(provider.Present("*.example.com", "token", "wildcard-key-auth"))
+	a.Nil(provider.Present("example.com", "token", "apex-key-auth"))
+
+	records := server.Records("", "example.com")
+	a.Len(records, 3)
+	values := make(map[string]string)
+	for _, record := range records {
+		a.Equal(zeit.RecordTypeTXT, record.Type)
+		a.Equal(dns01.DefaultTTL, record.TTL)
+		values[record.Value] = record.Name
+	}
+	a.Equal("_acme-challenge.www", values[dns01.ChallengeValue("key-auth")])
+	a.Equal("_acme-challenge", values[dns01.ChallengeValue("wildcard-key-auth")])
+	a.Equal("_acme-challenge", values[dns01.ChallengeValue("apex-key-auth")])
+	a.Empty(server.Records("", "example.net"))
+
+	a.Nil(provider.CleanUp("*.example.com", "token", "wildcard-key-auth"))
+	records = server.Records("", "example.com")
+	a.Len(records, 2, "only the record of the challenge should be removed")
+	a.Nil(provider.CleanUp("*.example.com", "token", "wildcard-key-auth"), "cleaning up twice should be ignored")
+	a.Nil(provider.CleanUp("www.example.com", "token", "key-auth"))
+	a.Nil(provider.CleanUp("example.com", "token", "apex-key-auth"))
+	a.Empty(server.Records("", "example.com"))
+
+	a.Error(provider.Present("example.org", "token", "key-auth"), "domains not in the account should fail")
+}
+
+func TestProvider_Config(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	client := zeit.NewClient(server.Token, zeit.WithBaseURL(server.URL))
+
+	provider := dns01.NewProvider(client, dns01.Config{
+		Zone:               "example.com",
+		TTL:                120,
+		PropagationTimeout: 5 * time.Minute,
+		PollingInterval:    10 * time.Second,
+	})
+	timeout, interval := provider.Timeout()
+	a.Equal(5*time.Minute, timeout)
+	a.Equal(10*time.Second, interval)
+
+	a.Nil(provider.Present("a.b.example.com", "token", "key-auth"))
+	records := server.Records("", "example.com")
+	a.Len(records, 1)
+	a.Equal("_acme-challenge.a.b", records[0].Name)
+	a.Equal(120, records[0].TTL)
+
+	a.Error(provider.Present("example.net", "token", "key-auth"), "domains outside the zone should fail")
+}
@@ -6,8 +6,8 @@ import (
 	"fmt"
 	"github.com/golang/mock/gomock"
 	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/kochie/zeit-api-go/zeittest"
 	"github.com/stretchr/testify/assert"
-	"io/ioutil"
 	"net/http"
 	"testing"
 )
@@ -35,11 +35,10 @@ func TestClient_ListDNSRecords(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -89,11 +88,10 @@ func TestClient_CreateDNSRecord(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -112,24 +110,24 @@ func TestClient_CreateDNSRecord(t *testing.T) {
 	}{
 		{
 			"www.test.com",
-			BasicError{},
+			&APIError{},
 			http.StatusBadRequest,
-			&Record{},
+			&Record{Name: "foo", Type: RecordTypeA, Value: "1.1.1.1"},
 		},
 		{
 			"foo.test.com",
-			ConflictError{},
+			&ConflictError{APIError: &APIError{}},
 			http.StatusConflict,
-			&Record{},
+			&Record{Name: "foo", Type: RecordTypeA, Value: "1.1.1.1"},
 		},
 		{
 			"foo.test.com",
-			BasicError{
-				"invalid_value",
-				"Invalid record value: \"0 issue letsencrypt.org\"",
+			&APIError{
+				Code:    "invalid_value",
+				Message: "Invalid record value: \"0 issue letsencrypt.org\"",
 			},
 			http.StatusBadRequest,
-			&Record{},
+			&Record{Name: "foo", Type: RecordTypeA, Value: "1.1.1.1"},
 		},
 		{"test.com", errors.New(ErrorOrigin), -1, &Record{
 			Name:  "@",
@@ -160,17 +158,25 @@ func TestClient_CreateDNSRecord(t *testing.T) {
 			}
 
 			client := Client{
-				TestToken,
-				rootUrl,
-				mockHttpClient,
-				&rateLimit{},
-				"",
+				token:      TestToken,
+				rootUrl:    rootUrl,
+				httpClient: mockHttpClient,
+				rateLimit:  &rateLimit{},
 			}
 
 			uid, err := client.CreateDNSRecord(badRequest.domain, badRequest.record)
 			a.Error(err, badRequest.response.Error())
 			a.IsType(badRequest.response, err)
 			a.Empty(uid, "uid should be empty")
+
+			var apiError *APIError
+			if errors.As(badRequest.response, &apiError) {
+				var actualError *APIError
+				a.True(errors.As(err, &actualError), "should wrap an APIError")
+				a.Equal(badRequest.statusCode, actualError.StatusCode, "status code should match")
+				a.Equal(apiError.Code, actualError.Code, "code should match")
+				a.Equal(apiError.Message, actualError.Message, "message should match")
+			}
 		})
 	}
 }
@@ -188,17 +194,14 @@ func TestClient_RemoveDNSRecord(t *testing.T) {
 	}
 
 	for domainName, recordId := range domainNames {
-		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{
-			StatusCode: http.StatusOK,
-			Body:       ioutil.NopCloser(nil),
-		}, nil)
+		httpResponse := makeResponse([]byte(`{}`), http.StatusOK)
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -207,3 +210,74 @@ func TestClient_RemoveDNSRecord(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_UpdateDNSRecord(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	recId, err := server.AddRecord("", "example.com", zeittest.Record{Name: "www", Type: RecordTypeA, Value: "1.1.1.1"})
+	a.Nil(err)
+
+	err = client.UpdateDNSRecord("example.com", recId, &Record{Name: "www", Type: RecordTypeA, Value: "1.0.0.1"})
+	a.Nil(err, "Error should be nil")
+
+	records := server.Records("", "example.com")
+	a.Len(records, 1)
+	a.Equal(recId, records[0].Id, "record should keep its id")
+	a.Equal("1.0.0.1", records[0].Value, "record should be updated")
+
+	err = client.UpdateDNSRecord("example.com", "missing", &Record{Name: "www", Type: RecordTypeA, Value: "1.0.0.1"})
+	a.True(errors.Is(err, ErrNotFound), "missing records should not be found")
+}
+
+func TestClient_ReplaceDNSRecord(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	recId, err := server.AddRecord("", "example.com", zeittest.Record{Name: "www", Type: RecordTypeA, Value: "1.1.1.1"})
+	a.Nil(err)
+
+	t.Run("update in place", func(t *testing.T) {
+		uid, err := client.ReplaceDNSRecord("example.com", recId, &Record{Name: "www", Type: RecordTypeA, Value: "1.0.0.1"})
+		a.Nil(err, "Error should be nil")
+		a.Equal(recId, uid, "record should be updated in place")
+	})
+
+	server.Handle(http.MethodPatch, "/v2/domains/example.com/records/"+recId, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	t.Run("create then remove", func(t *testing.T) {
+		uid, err := client.ReplaceDNSRecord("example.com", recId, &Record{Name: "www", Type: RecordTypeAAAA, Value: "::1"})
+		a.Nil(err, "Error should be nil")
+		a.NotEqual(recId, uid, "a new record should be created")
+
+		records := server.Records("", "example.com")
+		a.Len(records, 1, "old record should be removed")
+		a.Equal(uid, records[0].Id)
+		a.Equal("::1", records[0].Value)
+		recId = uid
+	})
+
+	server.Handle(http.MethodPatch, "/v2/domains/example.com/records/"+recId, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+	server.Handle(http.MethodDelete, "/v2/domains/example.com/records/"+recId, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		_, err := client.ReplaceDNSRecord("example.com", recId, &Record{Name: "www", Type: RecordTypeA, Value: "1.1.1.1"})
+		a.True(errors.Is(err, ErrForbidden), "should return the error removing the old record")
+
+		records := server.Records("", "example.com")
+		a.Len(records, 1, "new record should be rolled back")
+		a.Equal(recId, records[0].Id)
+	})
+}
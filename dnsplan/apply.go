@@ -0,0 +1,66 @@
+package dnsplan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/kochie/zeit-api-go"
+)
+
+// ErrTooManyDeletes is returned by Apply when the plan deletes more records than its MaxDeletes allows.
+var ErrTooManyDeletes = errors.New("dnsplan: plan deletes too many records")
+
+// Apply will execute the plan with CreateDNSRecord, ReplaceDNSRecord and RemoveDNSRecord. Records are created before
+// any are removed so names keep resolving while they are changed, and updates change records in place where the API
+// supports it, otherwise the changed record is created before the old one is removed. A CNAME can't share its name
+// with other records, so at names where a CNAME is created or deleted the deletes are made first, and a CNAME can only
+// be updated by an API that changes records in place. Apply
+// stops at the first change that fails, the changes before it are not rolled back, computing a new plan picks up from
+// where it stopped.
+func Apply(ctx context.Context, client Client, plan *Plan) error {
+	if deletes := plan.Count(Delete); plan.MaxDeletes >= 0 && deletes > plan.MaxDeletes {
+		return fmt.Errorf("%w: %d deletes, limit is %d", ErrTooManyDeletes, deletes, plan.MaxDeletes)
+	}
+
+	cnames := make(map[string]bool)
+	for _, change := range plan.Changes {
+		if change.Record.Type == zeit.RecordTypeCNAME && change.Action != Update {
+			cnames[change.Record.Name] = true
+		}
+	}
+	steps := []func(Change) bool{
+		func(change Change) bool { return change.Action == Delete && cnames[change.Record.Name] },
+		func(change Change) bool { return change.Action == Create },
+		func(change Change) bool { return change.Action == Update },
+		func(change Change) bool { return change.Action == Delete && !cnames[change.Record.Name] },
+	}
+	for _, step := range steps {
+		for _, change := range plan.Changes {
+			if !step(change) {
+				continue
+			}
+			if err := apply(ctx, client, plan.Domain, change); err != nil {
+				return fmt.Errorf("%s: %w", change, err)
+			}
+		}
+	}
+	return nil
+}
+
+func apply(ctx context.Context, client Client, domain string, change Change) error {
+	switch change.Action {
+	case Create:
+		record := change.Record
+		_, err := client.CreateDNSRecordContext(ctx, domain, &record)
+		return err
+	case Update:
+		record := change.Record
+		_, err := client.ReplaceDNSRecordContext(ctx, domain, change.Existing.Id, &record)
+		return err
+	case Delete:
+		return client.RemoveDNSRecordContext(ctx, domain, change.Record.Id)
+	default:
+		return fmt.Errorf("dnsplan: unknown action %q", change.Action)
+	}
+}
@@ -0,0 +1,319 @@
+// Package dnsplan converges the DNS records of a domain to a desired state. Compute compares the desired records with
+// the records returned by ListDNSRecords and produces a Plan of creates, updates and deletes that can be reviewed
+// before it is executed with Apply.
+//
+//	plan, err := dnsplan.Compute(ctx, client, "example.com", desired, dnsplan.Options{Owner: "infra"})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(plan)
+//	err = dnsplan.Apply(ctx, client, plan)
+//
+// When an Owner is set every name the plan manages is marked with a TXT record, records at names without a marker
+// belonging to the owner are never updated or deleted.
+package dnsplan
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kochie/zeit-api-go"
+)
+
+// DefaultMaxDeletes is the most records a plan may delete when Options.MaxDeletes isn't set.
+const DefaultMaxDeletes = 10
+
+// ownerPrefix is the label of the TXT record that marks a name as managed, the marker of www is _dnsplan.www.
+const ownerPrefix = "_dnsplan"
+
+// Client is the part of *zeit.Client used to compute and apply plans.
+type Client interface {
+	ListDNSRecordsContext(ctx context.Context, domain string, opts ...zeit.ListOption) ([]zeit.Record, error)
+	CreateDNSRecordContext(ctx context.Context, domain string, record *zeit.Record) (string, error)
+	RemoveDNSRecordContext(ctx context.Context, domain, recId string) error
+	ReplaceDNSRecordContext(ctx context.Context, domain, recId string, record *zeit.Record) (string, error)
+}
+
+// Action is what a Change does to a record.
+type Action string
+
+const (
+	Create Action = "create"
+	Update Action = "update"
+	Delete Action = "delete"
+)
+
+// Change is a single step of a Plan.
+type Change struct {
+	Action Action
+	// Record is the record to create, the new value of an updated record or the record to delete.
+	Record zeit.Record
+	// Existing is the record replaced by an update.
+	Existing *zeit.Record
+}
+
+func (c Change) String() string {
+	name := c.Record.Name
+	if name == "" {
+		name = "@"
+	}
+	switch c.Action {
+	case Create:
+		return fmt.Sprintf("+ %s %s %s", name, c.Record.Type, c.Record.GetValue())
+	case Delete:
+		return fmt.Sprintf("- %s %s %s", name, c.Record.Type, c.Record.GetValue())
+	default:
+		return fmt.Sprintf("~ %s %s %s -> %s", name, c.Record.Type, c.Existing.GetValue(), c.Record.GetValue())
+	}
+}
+
+// Options changes how a plan is computed.
+type Options struct {
+	// Owner identifies who manages the records. If set, only names marked as belonging to the owner are updated or
+	// deleted, and every name with desired records is marked. If empty, every record of the domain is managed.
+	Owner string
+	// MaxDeletes is the most records the plan may delete, Apply refuses to run a plan that deletes more. Zero uses
+	// DefaultMaxDeletes and a negative value removes the limit. Updates don't count as each one leaves a record with the
+	// changed value in place of the old one, even when the API can't update records in place.
+	MaxDeletes int
+}
+
+// Plan is the set of changes that converge a domain to its desired records.
+type Plan struct {
+	Domain     string
+	Changes    []Change
+	MaxDeletes int
+}
+
+// Empty reports whether the domain already has the desired records.
+func (p *Plan) Empty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes with the action.
+func (p *Plan) Count(action Action) int {
+	n := 0
+	for _, change := range p.Changes {
+		if change.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// String returns a human readable diff of the plan, with one line per change.
+func (p *Plan) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d to create, %d to update, %d to delete\n",
+		p.Domain, p.Count(Create), p.Count(Update), p.Count(Delete))
+	for _, change := range p.Changes {
+		b.WriteString(change.String())
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// key groups records that can replace each other.
+type key struct {
+	name       string
+	recordType string
+}
+
+// sameValue reports whether two records have the same value, hostnames are compared without case or trailing dot.
+func sameValue(a, b *zeit.Record) bool {
+	if b.TTL != 0 && a.TTL != 0 && a.TTL != b.TTL {
+		return false
+	}
+	return strings.EqualFold(normalise(a), normalise(b))
+}
+
+func normalise(r *zeit.Record) string {
+	return strings.TrimSuffix(strings.TrimSpace(r.GetValue()), ".")
+}
+
+// wildcardLabel replaces the * label of wildcard names in the name of their marker, as * is only valid as the first
+// label of a name. The marker of *.dev is _dnsplan._wildcard.dev.
+const wildcardLabel = "_wildcard"
+
+// ownerName returns the name of the TXT record that marks the name as managed.
+func ownerName(name string) string {
+	if name == "" {
+		return ownerPrefix
+	}
+	if name == "*" || strings.HasPrefix(name, "*.") {
+		name = wildcardLabel + strings.TrimPrefix(name, "*")
+	}
+	return ownerPrefix + "." + name
+}
+
+// markedName returns the name marked as managed by the TXT record with the name, the reverse of ownerName.
+func markedName(marker string) string {
+	name := strings.TrimPrefix(strings.TrimPrefix(marker, ownerPrefix), ".")
+	if name == wildcardLabel || strings.HasPrefix(name, wildcardLabel+".") {
+		name = "*" + strings.TrimPrefix(name, wildcardLabel)
+	}
+	return name
+}
+
+// ownerValue returns the value of the TXT record that marks a name as belonging to the owner.
+func ownerValue(owner string) string {
+	return "heritage=dnsplan,owner=" + owner
+}
+
+// isMarker reports whether the record marks a name as managed by any owner.
+func isMarker(r *zeit.Record) bool {
+	return r.Type == zeit.RecordTypeTXT && strings.HasPrefix(r.Value, "heritage=dnsplan,") &&
+		(r.Name == ownerPrefix || strings.HasPrefix(r.Name, ownerPrefix+"."))
+}
+
+// Compute compares the desired records of the domain with its current records and returns the plan that converges
+// them. Records with the same name and type are updated in place of deleting one and creating the other. The desired
+// records are checked with zeit.ValidateRecords first.
+func Compute(ctx context.Context, client Client, domain string, desired []zeit.Record, opts Options) (*Plan, error) {
+	if err := zeit.ValidateRecords(desired); err != nil {
+		return nil, err
+	}
+	existing, err := client.ListDNSRecordsContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	maxDeletes := opts.MaxDeletes
+	if maxDeletes == 0 {
+		maxDeletes = DefaultMaxDeletes
+	}
+	plan := &Plan{Domain: domain, MaxDeletes: maxDeletes}
+
+	// find the names that belong to the owner, and their markers
+	owned := make(map[string]bool)
+	markers := make(map[string]zeit.Record)
+	current := make(map[key][]zeit.Record)
+	for _, record := range existing {
+		if isMarker(&record) {
+			if opts.Owner != "" && record.Value == ownerValue(opts.Owner) {
+				name := markedName(record.Name)
+				owned[name] = true
+				markers[name] = record
+			}
+			continue
+		}
+		k := key{record.Name, record.Type}
+		current[k] = append(current[k], record)
+	}
+	managed := func(name string) bool {
+		return opts.Owner == "" || owned[name]
+	}
+
+	wanted := make(map[key][]zeit.Record)
+	wantedNames := make(map[string]bool)
+	for _, record := range desired {
+		k := key{record.Name, record.Type}
+		wanted[k] = append(wanted[k], record)
+		wantedNames[record.Name] = true
+	}
+
+	for _, k := range sortedKeys(current, wanted) {
+		plan.Changes = append(plan.Changes, diff(current[k], wanted[k], managed(k.name))...)
+	}
+
+	// mark new names as managed and remove the markers of names that are no longer wanted
+	if opts.Owner != "" {
+		for _, name := range sortedNames(wantedNames) {
+			if !owned[name] && !hasUnmanaged(current, name) {
+				plan.Changes = append(plan.Changes, Change{Action: Create, Record: zeit.Record{
+					Name: ownerName(name), Type: zeit.RecordTypeTXT, Value: ownerValue(opts.Owner),
+				}})
+			}
+		}
+		for _, name := range sortedNames(owned) {
+			if !wantedNames[name] {
+				plan.Changes = append(plan.Changes, Change{Action: Delete, Record: markers[name]})
+			}
+		}
+	}
+	return plan, nil
+}
+
+// diff returns the changes that turn the current records with a name and type into the wanted records. Records that
+// already have a wanted value are kept, the rest are paired up as updates with any left over created or deleted.
+// Unmanaged records are never updated or deleted.
+func diff(current, wanted []zeit.Record, managed bool) []Change {
+	var stale, missing []zeit.Record
+	matched := make([]bool, len(wanted))
+	for _, record := range current {
+		found := false
+		for i := range wanted {
+			if !matched[i] && sameValue(&record, &wanted[i]) {
+				matched[i], found = true, true
+				break
+			}
+		}
+		if !found {
+			stale = append(stale, record)
+		}
+	}
+	for i, record := range wanted {
+		if !matched[i] {
+			missing = append(missing, record)
+		}
+	}
+	if !managed {
+		stale = nil
+	}
+
+	var changes []Change
+	for len(stale) > 0 && len(missing) > 0 {
+		existing := stale[0]
+		changes = append(changes, Change{Action: Update, Record: missing[0], Existing: &existing})
+		stale, missing = stale[1:], missing[1:]
+	}
+	for _, record := range missing {
+		changes = append(changes, Change{Action: Create, Record: record})
+	}
+	for _, record := range stale {
+		changes = append(changes, Change{Action: Delete, Record: record})
+	}
+	return changes
+}
+
+// hasUnmanaged reports whether the name already has records, in which case it isn't marked as owned as the plan
+// doesn't manage the records that were there first.
+func hasUnmanaged(current map[key][]zeit.Record, name string) bool {
+	for k := range current {
+		if k.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(maps ...map[key][]zeit.Record) []key {
+	seen := make(map[key]bool)
+	var keys []key
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].recordType < keys[j].recordType
+	})
+	return keys
+}
+
+func sortedNames(names map[string]bool) []string {
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
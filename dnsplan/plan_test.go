@@ -0,0 +1,238 @@
+package dnsplan_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/kochie/zeit-api-go"
+	"github.com/kochie/zeit-api-go/dnsplan"
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer() (*zeittest.Server, *zeit.Client) {
+	server := zeittest.NewServer()
+	server.AddDomain("", "example.com")
+	return server, zeit.NewClient(server.Token, zeit.WithBaseURL(server.URL))
+}
+
+func TestCompute(t *testing.T) {
+	a := assert.New(t)
+	server, client := newServer()
+	defer server.Close()
+
+	for _, record := range []zeittest.Record{
+		{Name: "www", Type: zeit.RecordTypeA, Value: "1.1.1.1"},
+		{Name: "old", Type: zeit.RecordTypeA, Value: "2.2.2.2"},
+		{Name: "api", Type: zeit.RecordTypeCNAME, Value: "a.example.com"},
+	} {
+		_, err := server.AddRecord("", "example.com", record)
+		a.Nil(err)
+	}
+
+	desired := []zeit.Record{
+		{Name: "www", Type: zeit.RecordTypeA, Value: "1.1.1.1"},
+		{Name: "api", Type: zeit.RecordTypeCNAME, Value: "b.example.com."},
+		{Name: "new", Type: zeit.RecordTypeTXT, Value: `"hello"`},
+	}
+	plan, err := dnsplan.Compute(context.Background(), client, "example.com", desired, dnsplan.Options{})
+	a.Nil(err, "Error should be nil")
+	a.Equal("example.com: 1 to create, 1 to update, 1 to delete\n"+
+		"~ api CNAME a.example.com -> b.example.com.\n"+
+		"+ new TXT \"hello\"\n"+
+		"- old A 2.2.2.2\n", plan.String())
+
+	err = dnsplan.Apply(context.Background(), client, plan)
+	a.Nil(err, "Error should be nil")
+	a.Len(server.Records("", "example.com"), 3)
+
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", desired, dnsplan.Options{})
+	a.Nil(err, "Error should be nil")
+	a.True(plan.Empty(), "applied plan should converge, got %s", plan)
+
+	_, err = dnsplan.Compute(context.Background(), client, "example.com", []zeit.Record{
+		{Name: "www", Type: zeit.RecordTypeA, Value: "not an address"},
+	}, dnsplan.Options{})
+	var validationError *zeit.ValidationError
+	a.True(errors.As(err, &validationError), "desired records should be validated")
+}
+
+func TestCompute_Owner(t *testing.T) {
+	a := assert.New(t)
+	server, client := newServer()
+	defer server.Close()
+
+	_, err := server.AddRecord("", "example.com", zeittest.Record{Name: "mail", Type: zeit.RecordTypeA, Value: "3.3.3.3"})
+	a.Nil(err)
+
+	opts := dnsplan.Options{Owner: "infra"}
+	desired := []zeit.Record{{Name: "www", Type: zeit.RecordTypeA, Value: "1.1.1.1"}}
+	plan, err := dnsplan.Compute(context.Background(), client, "example.com", desired, opts)
+	a.Nil(err, "Error should be nil")
+	a.Equal(2, plan.Count(dnsplan.Create), "record and marker should be created")
+	a.Equal(0, plan.Count(dnsplan.Delete), "unmanaged records should be left alone")
+	a.Nil(dnsplan.Apply(context.Background(), client, plan))
+
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", nil, opts)
+	a.Nil(err, "Error should be nil")
+	a.Equal("example.com: 0 to create, 0 to update, 2 to delete\n"+
+		"- www A 1.1.1.1\n"+
+		"- _dnsplan.www TXT heritage=dnsplan,owner=infra\n", plan.String())
+	a.Nil(dnsplan.Apply(context.Background(), client, plan))
+
+	records := server.Records("", "example.com")
+	a.Len(records, 1)
+	a.Equal("mail", records[0].Name, "unmanaged record should be kept")
+
+	// names that already have records are not taken over
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", []zeit.Record{
+		{Name: "mail", Type: zeit.RecordTypeA, Value: "4.4.4.4"},
+	}, opts)
+	a.Nil(err, "Error should be nil")
+	a.Equal("example.com: 1 to create, 0 to update, 0 to delete\n"+
+		"+ mail A 4.4.4.4\n", plan.String())
+}
+
+func TestCompute_OwnerWildcard(t *testing.T) {
+	a := assert.New(t)
+	server, client := newServer()
+	defer server.Close()
+
+	opts := dnsplan.Options{Owner: "infra"}
+	desired := []zeit.Record{
+		{Name: "*", Type: zeit.RecordTypeA, Value: "1.1.1.1"},
+		{Name: "*.dev", Type: zeit.RecordTypeA, Value: "2.2.2.2"},
+	}
+	plan, err := dnsplan.Compute(context.Background(), client, "example.com", desired, opts)
+	a.Nil(err, "Error should be nil")
+	a.Equal("example.com: 4 to create, 0 to update, 0 to delete\n"+
+		"+ * A 1.1.1.1\n"+
+		"+ *.dev A 2.2.2.2\n"+
+		"+ _dnsplan._wildcard TXT heritage=dnsplan,owner=infra\n"+
+		"+ _dnsplan._wildcard.dev TXT heritage=dnsplan,owner=infra\n", plan.String())
+	a.Nil(dnsplan.Apply(context.Background(), client, plan))
+
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", desired, opts)
+	a.Nil(err, "Error should be nil")
+	a.True(plan.Empty(), "wildcard names should be recognised as owned, got %s", plan)
+
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", nil, opts)
+	a.Nil(err, "Error should be nil")
+	a.Equal(4, plan.Count(dnsplan.Delete), "owned wildcard records should be deleted")
+}
+
+// recordingClient logs the changes made through it.
+type recordingClient struct {
+	*zeit.Client
+	log []string
+}
+
+func (c *recordingClient) CreateDNSRecordContext(ctx context.Context, domain string, record *zeit.Record) (string, error) {
+	c.log = append(c.log, "create "+record.Name+" "+record.Type)
+	return c.Client.CreateDNSRecordContext(ctx, domain, record)
+}
+
+func (c *recordingClient) ReplaceDNSRecordContext(ctx context.Context, domain, recId string, record *zeit.Record) (string, error) {
+	c.log = append(c.log, "replace "+record.Name+" "+record.Type)
+	return c.Client.ReplaceDNSRecordContext(ctx, domain, recId, record)
+}
+
+func (c *recordingClient) RemoveDNSRecordContext(ctx context.Context, domain, recId string) error {
+	c.log = append(c.log, "remove "+recId)
+	return c.Client.RemoveDNSRecordContext(ctx, domain, recId)
+}
+
+func TestApply_CNAME(t *testing.T) {
+	a := assert.New(t)
+	server, zeitClient := newServer()
+	defer server.Close()
+	client := &recordingClient{Client: zeitClient}
+
+	ids := make(map[string]string)
+	for _, record := range []zeittest.Record{
+		{Name: "api", Type: zeit.RecordTypeCNAME, Value: "a.example.com"},
+		{Name: "www", Type: zeit.RecordTypeCNAME, Value: "a.example.com"},
+		{Name: "old", Type: zeit.RecordTypeA, Value: "2.2.2.2"},
+	} {
+		id, err := server.AddRecord("", "example.com", record)
+		a.Nil(err)
+		ids[record.Name] = id
+	}
+
+	desired := []zeit.Record{
+		{Name: "api", Type: zeit.RecordTypeCNAME, Value: "b.example.com"},
+		{Name: "www", Type: zeit.RecordTypeA, Value: "1.1.1.1"},
+	}
+	plan, err := dnsplan.Compute(context.Background(), client, "example.com", desired, dnsplan.Options{})
+	a.Nil(err, "Error should be nil")
+	a.Nil(dnsplan.Apply(context.Background(), client, plan))
+
+	// the CNAME at www is removed before the A record replacing it is created, and the target of api is changed in
+	// place so there are never two records at either name
+	a.Equal([]string{
+		"remove " + ids["www"],
+		"create www A",
+		"replace api CNAME",
+		"remove " + ids["old"],
+	}, client.log)
+
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", desired, dnsplan.Options{})
+	a.Nil(err, "Error should be nil")
+	a.True(plan.Empty(), "applied plan should converge, got %s", plan)
+
+	// an API that can't update records in place has the changed record created before the old one is removed
+	records := server.Records("", "example.com")
+	for _, record := range records {
+		server.Handle(http.MethodPatch, "/v2/domains/example.com/records/"+record.Id, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			_, _ = w.Write([]byte(`{"error": {"code": "method_not_allowed", "message": "Method not allowed"}}`))
+		})
+	}
+	var wwwId string
+	for _, record := range records {
+		if record.Name == "www" {
+			wwwId = record.Id
+		}
+	}
+	desired[1].Value = "3.3.3.3"
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", desired, dnsplan.Options{})
+	a.Nil(err, "Error should be nil")
+	a.Equal(1, plan.Count(dnsplan.Update))
+	a.Nil(dnsplan.Apply(context.Background(), client, plan))
+
+	plan, err = dnsplan.Compute(context.Background(), client, "example.com", desired, dnsplan.Options{})
+	a.Nil(err, "Error should be nil")
+	a.True(plan.Empty(), "applied plan should converge without updating in place, got %s", plan)
+	a.Len(server.Records("", "example.com"), len(records), "the old record should be removed")
+	for _, record := range server.Records("", "example.com") {
+		if record.Name == "www" {
+			a.Equal("3.3.3.3", record.Value)
+			a.NotEqual(wwwId, record.Id, "the record should have been replaced")
+		}
+	}
+}
+
+func TestApply_MaxDeletes(t *testing.T) {
+	a := assert.New(t)
+	server, client := newServer()
+	defer server.Close()
+
+	for _, value := range []string{"1.1.1.1", "1.0.0.1", "8.8.8.8"} {
+		_, err := server.AddRecord("", "example.com", zeittest.Record{Name: "www", Type: zeit.RecordTypeA, Value: value})
+		a.Nil(err)
+	}
+
+	plan, err := dnsplan.Compute(context.Background(), client, "example.com", nil, dnsplan.Options{MaxDeletes: 2})
+	a.Nil(err, "Error should be nil")
+	a.Equal(3, plan.Count(dnsplan.Delete))
+
+	err = dnsplan.Apply(context.Background(), client, plan)
+	a.True(errors.Is(err, dnsplan.ErrTooManyDeletes), "plan should be refused")
+	a.Len(server.Records("", "example.com"), 3, "no records should be deleted")
+
+	plan.MaxDeletes = -1
+	a.Nil(dnsplan.Apply(context.Background(), client, plan))
+	a.Empty(server.Records("", "example.com"))
+}
@@ -1,11 +1,10 @@
 package zeit
 
 import (
-	"bytes"
-	"encoding/json"
-	"errors"
+	"context"
 	"fmt"
 	"net/http"
+	"net/url"
 )
 
 type User struct {
@@ -47,46 +46,43 @@ type Domain struct {
 	Certs               []Certs   `json:"certs,omitempty"`
 }
 
-// GetAllDomains will return a slice of domains registered with the user.
-func (c Client) ListAllDomains() ([]Domain, error) {
-	resp, err := c.makeAndDoRequest(http.MethodGet, "v4/domains", nil)
-	if err != nil {
-		return nil, err
-	}
-	defer closeResponseBody(resp)
-
-	domains := struct {
-		Domains []Domain `json:"domains"`
-	}{}
+// ListAllDomains will return a slice of domains registered with the user. Every page of domains is requested, use
+// IterateDomains to read them lazily instead.
+func (c Client) ListAllDomains(opts ...ListOption) ([]Domain, error) {
+	return c.ListAllDomainsContext(context.Background(), opts...)
+}
 
-	err = json.NewDecoder(resp.Body).Decode(&domains)
-	if err != nil {
+// ListAllDomainsContext is the same as ListAllDomains but the requests are bound to the given context.
+func (c Client) ListAllDomainsContext(ctx context.Context, opts ...ListOption) ([]Domain, error) {
+	domains := make([]Domain, 0)
+	it := c.IterateDomains(opts...)
+	for it.Next(ctx) {
+		domains = append(domains, it.Value())
+	}
+	if err := it.Err(); err != nil {
 		return nil, err
 	}
-
-	return domains.Domains, nil
+	return domains, nil
 }
 
 // AddDomain will add a specified domain name to ZEIT, either as an external or internal domain.
 func (c Client) AddDomain(name string) (*Domain, error) {
-	parameters := struct {
-		Name string `json:"name"`
-	}{name}
-	body, err := json.Marshal(parameters)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.makeAndDoRequest(http.MethodPost, "v4/domains", bytes.NewBuffer(body))
+	return c.AddDomainContext(context.Background(), name)
+}
 
-	defer closeResponseBody(resp)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
-	}
+// AddDomainContext is the same as AddDomain but the request is bound to the given context.
+func (c Client) AddDomainContext(ctx context.Context, name string) (*Domain, error) {
 	domain := Domain{}
-	err = json.NewDecoder(resp.Body).Decode(&domain)
+	err := c.do(ctx, request{
+		method:   http.MethodPost,
+		endpoint: "v4/domains",
+		body: struct {
+			Name string `json:"name"`
+		}{name},
+		result: &struct {
+			Domain *Domain `json:"domain"`
+		}{&domain},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -95,27 +91,25 @@ func (c Client) AddDomain(name string) (*Domain, error) {
 
 // TransferInDomain will initiate a domain transfer request from an external Registrar to ZEIT.
 func (c Client) TransferInDomain(name, authCode string, expectedPrice int) (*Domain, error) {
-	parameters := struct {
-		Method        string `json:"method"`
-		Name          string `json:"name"`
-		AuthCode      string `json:"authCode"`
-		ExpectedPrice int    `json:"expectedPrice"`
-	}{"transfer-in", name, authCode, expectedPrice}
-	body, err := json.Marshal(parameters)
-	if err != nil {
-		return nil, err
-	}
-	resp, err := c.makeAndDoRequest(http.MethodPost, "v4/domains", bytes.NewBuffer(body))
-	defer closeResponseBody(resp)
+	return c.TransferInDomainContext(context.Background(), name, authCode, expectedPrice)
+}
 
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(resp.Status)
-	}
+// TransferInDomainContext is the same as TransferInDomain but the request is bound to the given context.
+func (c Client) TransferInDomainContext(ctx context.Context, name, authCode string, expectedPrice int) (*Domain, error) {
 	domain := Domain{}
-	err = json.NewDecoder(resp.Body).Decode(&domain)
+	err := c.do(ctx, request{
+		method:   http.MethodPost,
+		endpoint: "v4/domains",
+		body: struct {
+			Method        string `json:"method"`
+			Name          string `json:"name"`
+			AuthCode      string `json:"authCode"`
+			ExpectedPrice int    `json:"expectedPrice"`
+		}{"transfer-in", name, authCode, expectedPrice},
+		result: &struct {
+			Domain *Domain `json:"domain"`
+		}{&domain},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -125,26 +119,20 @@ func (c Client) TransferInDomain(name, authCode string, expectedPrice int) (*Dom
 // VerifyDomain will check if the domain either has the correct nameservers for ZEIT defined or if the DNS TXT
 // verification is set.
 func (c Client) VerifyDomain(name string) (*Domain, error) {
-	endpoint := fmt.Sprintf("v4/domains/%s/verify", name)
-	resp, err := c.makeAndDoRequest(http.MethodPost, endpoint, nil)
-	defer closeResponseBody(resp)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		verificationError := VerificationError{}
-		err := json.NewDecoder(resp.Body).Decode(&struct {
-			Error VerificationError
-		}{verificationError})
-		if err != nil {
-			return nil, err
-		}
-		return nil, verificationError
-	}
+	return c.VerifyDomainContext(context.Background(), name)
+}
+
+// VerifyDomainContext is the same as VerifyDomain but the request is bound to the given context.
+func (c Client) VerifyDomainContext(ctx context.Context, name string) (*Domain, error) {
 	domain := Domain{}
-	err = json.NewDecoder(resp.Body).Decode(&struct {
-		Domain Domain
-	}{domain})
+	err := c.do(ctx, request{
+		method:   http.MethodPost,
+		endpoint: fmt.Sprintf("v4/domains/%s/verify", name),
+		result: &struct {
+			Domain *Domain `json:"domain"`
+		}{&domain},
+		wrapError: newVerificationError,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -153,26 +141,20 @@ func (c Client) VerifyDomain(name string) (*Domain, error) {
 
 // GetDomain will return specific information for one domain.
 func (c Client) GetDomain(name string) (*Domain, error) {
-	endpoint := fmt.Sprintf("v4/domains/%s", name)
-	resp, err := c.makeAndDoRequest(http.MethodGet, endpoint, nil)
-	defer closeResponseBody(resp)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		getError := GetError{}
-		err := json.NewDecoder(resp.Body).Decode(&struct {
-			Error GetError
-		}{getError})
-		if err != nil {
-			return nil, err
-		}
-		return nil, getError
-	}
+	return c.GetDomainContext(context.Background(), name)
+}
+
+// GetDomainContext is the same as GetDomain but the request is bound to the given context.
+func (c Client) GetDomainContext(ctx context.Context, name string) (*Domain, error) {
 	domain := Domain{}
-	err = json.NewDecoder(resp.Body).Decode(&struct {
-		Domain Domain
-	}{domain})
+	err := c.do(ctx, request{
+		method:   http.MethodGet,
+		endpoint: fmt.Sprintf("v4/domains/%s", name),
+		result: &struct {
+			Domain *Domain `json:"domain"`
+		}{&domain},
+		wrapError: newGetError,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -181,18 +163,19 @@ func (c Client) GetDomain(name string) (*Domain, error) {
 
 // RemoveDomain will remove a domain from the ZEIT DNS server.
 func (c Client) RemoveDomain(name string) (string, error) {
-	endpoint := fmt.Sprintf("v4/domains/%s", name)
-	resp, err := c.makeAndDoRequest(http.MethodDelete, endpoint, nil)
-	if err != nil {
-		return "", err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return "", errors.New(resp.Status)
-	}
+	return c.RemoveDomainContext(context.Background(), name)
+}
+
+// RemoveDomainContext is the same as RemoveDomain but the request is bound to the given context.
+func (c Client) RemoveDomainContext(ctx context.Context, name string) (string, error) {
 	var uid string
-	err = json.NewDecoder(resp.Body).Decode(&struct {
-		Uid *string
-	}{&uid})
+	err := c.do(ctx, request{
+		method:   http.MethodDelete,
+		endpoint: fmt.Sprintf("v4/domains/%s", name),
+		result: &struct {
+			Uid *string `json:"uid"`
+		}{&uid},
+	})
 	if err != nil {
 		return "", err
 	}
@@ -201,18 +184,20 @@ func (c Client) RemoveDomain(name string) (string, error) {
 
 // CheckDomainAvailability will check if the specified domain is available for sale.
 func (c Client) CheckDomainAvailability(name string) (bool, error) {
-	endpoint := fmt.Sprintf("v4/domains/status?%s", name)
-	resp, err := c.makeAndDoRequest(http.MethodGet, endpoint, nil)
-	if err != nil {
-		return false, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return false, errors.New(resp.Status)
-	}
+	return c.CheckDomainAvailabilityContext(context.Background(), name)
+}
+
+// CheckDomainAvailabilityContext is the same as CheckDomainAvailability but the request is bound to the given context.
+func (c Client) CheckDomainAvailabilityContext(ctx context.Context, name string) (bool, error) {
 	var available bool
-	err = json.NewDecoder(resp.Body).Decode(&struct {
-		Available *bool
-	}{&available})
+	err := c.do(ctx, request{
+		method:   http.MethodGet,
+		endpoint: "v4/domains/status",
+		query:    url.Values{"name": {name}},
+		result: &struct {
+			Available *bool `json:"available"`
+		}{&available},
+	})
 	if err != nil {
 		return false, err
 	}
@@ -221,19 +206,21 @@ func (c Client) CheckDomainAvailability(name string) (bool, error) {
 
 // CheckDomainPrice will check how much a domain will cost to purchase and will return the price and period of purchase.
 func (c Client) CheckDomainPrice(name string) (int, int, error) {
-	endpoint := fmt.Sprintf("v4/domains/price?%s", name)
-	resp, err := c.makeAndDoRequest(http.MethodGet, endpoint, nil)
-	if err != nil {
-		return 0, 0, err
-	}
-	if resp.StatusCode != http.StatusOK {
-		return 0, 0, errors.New(resp.Status)
-	}
+	return c.CheckDomainPriceContext(context.Background(), name)
+}
+
+// CheckDomainPriceContext is the same as CheckDomainPrice but the request is bound to the given context.
+func (c Client) CheckDomainPriceContext(ctx context.Context, name string) (int, int, error) {
 	var price, period int
-	err = json.NewDecoder(resp.Body).Decode(&struct {
-		Price  *int
-		Period *int
-	}{&price, &period})
+	err := c.do(ctx, request{
+		method:   http.MethodGet,
+		endpoint: "v4/domains/price",
+		query:    url.Values{"name": {name}},
+		result: &struct {
+			Price  *int `json:"price"`
+			Period *int `json:"period"`
+		}{&price, &period},
+	})
 	if err != nil {
 		return 0, 0, err
 	}
@@ -242,31 +229,17 @@ func (c Client) CheckDomainPrice(name string) (int, int, error) {
 
 // BuyDomain will buy a domain name at the expectedPrice.
 func (c Client) BuyDomain(name string, expectedPrice int) error {
-	parameters := struct {
-		Name          string `json:"name"`
-		ExpectedPrice int    `json:"expectedPrice"`
-	}{name, expectedPrice}
-	body, err := json.Marshal(parameters)
-	if err != nil {
-		return err
-	}
-	resp, err := c.makeAndDoRequest(http.MethodPost, "v4/domains/buy", bytes.NewBuffer(body))
-	if err != nil {
-		return err
-	}
-	if resp.StatusCode == http.StatusForbidden {
-		buyError := BasicError{}
-		err := json.NewDecoder(resp.Body).Decode(&struct {
-			Error BasicError
-		}{buyError})
-		if err != nil {
-			return err
-		}
-		defer closeResponseBody(resp)
-		return buyError
-	}
-	if resp.StatusCode != http.StatusOK {
-		return errors.New(resp.Status)
-	}
-	return nil
+	return c.BuyDomainContext(context.Background(), name, expectedPrice)
+}
+
+// BuyDomainContext is the same as BuyDomain but the request is bound to the given context.
+func (c Client) BuyDomainContext(ctx context.Context, name string, expectedPrice int) error {
+	return c.do(ctx, request{
+		method:   http.MethodPost,
+		endpoint: "v4/domains/buy",
+		body: struct {
+			Name          string `json:"name"`
+			ExpectedPrice int    `json:"expectedPrice"`
+		}{name, expectedPrice},
+	})
 }
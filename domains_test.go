@@ -3,6 +3,7 @@ package zeit
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/kochie/zeit-api-go/mocks"
 	"github.com/stretchr/testify/assert"
@@ -46,11 +47,10 @@ func TestClient_GetAllDomains(t *testing.T) {
 	mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 	client := Client{
-		TestToken,
-		rootUrl,
-		mockHttpClient,
-		&rateLimit{},
-		"",
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
 	}
 	domains, err := client.ListAllDomains()
 
@@ -82,11 +82,10 @@ func TestClient_AddDomain(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -120,11 +119,10 @@ func TestClient_TransferInDomain(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -157,11 +155,10 @@ func TestClient_VerifyDomain(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -194,11 +191,10 @@ func TestClient_GetDomain(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -233,11 +229,10 @@ func TestClient_RemoveDomain(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -269,11 +264,10 @@ func TestClient_CheckDomainAvailability(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -306,11 +300,10 @@ func TestClient_CheckDomainPrice(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -338,11 +331,10 @@ func TestClient_BuyDomain(t *testing.T) {
 		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&http.Response{StatusCode: http.StatusOK}, nil)
 
 		client := Client{
-			TestToken,
-			rootUrl,
-			mockHttpClient,
-			&rateLimit{},
-			"",
+			token:      TestToken,
+			rootUrl:    rootUrl,
+			httpClient: mockHttpClient,
+			rateLimit:  &rateLimit{},
 		}
 
 		t.Run(domainName, func(t *testing.T) {
@@ -351,3 +343,60 @@ func TestClient_BuyDomain(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_GetDomain_NotFound(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	httpResponse := makeResponse(
+		[]byte(`{"error":{"code":"not_found","message":"The domain was not found","name":"test.com"}}`),
+		http.StatusNotFound,
+	)
+	mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
+
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	domain, err := client.GetDomain("test.com")
+	a.Nil(domain, "domain should be nil")
+	a.True(errors.Is(err, ErrNotFound), "should match ErrNotFound")
+
+	var getError *GetError
+	a.True(errors.As(err, &getError), "should be a GetError")
+	a.Equal("test.com", getError.Name, "should decode the name")
+	a.Equal("The domain was not found", getError.Message, "should decode the message")
+}
+
+func TestClient_VerifyDomain_Error(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	httpResponse := makeResponse([]byte(`{"error":{
+		"code":"verification_failed",
+		"message":"The domain couldn't be verified",
+		"name":"test.com",
+		"txtVerification":{"name":"test.com","values":[],"verificationRecord":"abc123"}
+	}}`), http.StatusBadRequest)
+	mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
+
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	_, err := client.VerifyDomain("test.com")
+	var verificationError *VerificationError
+	a.True(errors.As(err, &verificationError), "should be a VerificationError")
+	a.Equal("abc123", verificationError.TxtVerification.VerificationRecord, "should decode the verification record")
+	a.Equal(http.StatusBadRequest, verificationError.StatusCode, "should have the status code")
+}
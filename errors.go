@@ -1,30 +1,114 @@
 package zeit
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
 const ErrorOrigin = "zeit API does not use `@` to represent the origin, use empty string instead"
 const ErrorNilRecord = "pointer to record is nil"
 
-type BasicError struct {
-	Code    string `json:"code"`
+// Sentinel errors that an *APIError can be compared against with errors.Is, ErrRateLimited is also matched by errors
+// returned from a FailFastRateLimiter.
+var (
+	ErrNotFound  = errors.New("zeit resource not found")
+	ErrConflict  = errors.New("zeit resource conflict")
+	ErrForbidden = errors.New("zeit request forbidden")
+)
+
+// APIError is returned whenever the ZEIT API responds with an error status. Errors with extra detail, such as
+// ConflictError, wrap an APIError so it can always be retrieved with errors.As.
+type APIError struct {
+	// StatusCode is the http status code of the response.
+	StatusCode int `json:"-"`
+	// Code is the error code sent by the API, for example "not_found".
+	Code string `json:"code"`
+	// Message is the human readable error message sent by the API.
 	Message string `json:"message"`
+	// RequestID is the id the API assigned to the request, it is useful when contacting support.
+	RequestID string `json:"-"`
+	// Method is the http method of the request.
+	Method string `json:"-"`
+	// Endpoint is the path of the request.
+	Endpoint string `json:"-"`
+	// Body is the raw body of the response.
+	Body []byte `json:"-"`
+}
+
+// BasicError is the name APIError had before it carried the status code and request details.
+//
+// Deprecated: use APIError. Errors are returned as a *APIError, which errors.As can find.
+type BasicError = APIError
+
+func (e *APIError) Error() string {
+	message := e.Message
+	if message == "" {
+		message = http.StatusText(e.StatusCode)
+	}
+	if e.Code != "" {
+		message = fmt.Sprintf("%s: %s", e.Code, message)
+	}
+	if e.Method == "" && e.Endpoint == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, message)
+	}
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.Endpoint, e.StatusCode, message)
+}
+
+// Is reports whether the error matches one of the sentinel errors, ErrNotFound, ErrConflict, ErrForbidden or
+// ErrRateLimited, based on the status code of the response.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
 }
 
-func (e BasicError) Error() string {
-	return e.Message
+// decodeDetail will decode the error object of the response body into v, which should be one of the errors wrapping
+// the APIError.
+func (e *APIError) decodeDetail(v interface{}) {
+	// the detail is optional so a body that doesn't match is ignored
+	_ = json.Unmarshal(e.Body, &struct {
+		Error interface{} `json:"error"`
+	}{v})
 }
 
+// ConflictError is returned when a resource can't be created because it already exists.
 type ConflictError struct {
-	BasicError
+	*APIError
 	OldId  string   `json:"oldId"`
 	OldIds []string `json:"oldIds"`
 }
 
+func (e *ConflictError) Unwrap() error {
+	return e.APIError
+}
+
+// GetError is returned when a domain can't be retrieved.
 type GetError struct {
-	BasicError
+	*APIError
 	Name string `json:"name"`
 }
 
+func (e *GetError) Unwrap() error {
+	return e.APIError
+}
+
+// VerificationError is returned when a domain can't be verified, it contains the nameservers and TXT record that are
+// required to verify the domain.
 type VerificationError struct {
-	BasicError
+	*APIError
 	Name           string `json:"name"`
 	NsVerification struct {
 		Name                string   `json:"name"`
@@ -38,11 +122,104 @@ type VerificationError struct {
 	} `json:"txtVerification"`
 }
 
+func (e *VerificationError) Unwrap() error {
+	return e.APIError
+}
+
+// RateLimitError is returned when the API rejects a request because the rate limit has been reached and the request
+// couldn't be retried.
 type RateLimitError struct {
-	BasicError
+	*APIError
 	Limit struct {
 		Total     int
 		Remaining int
 		Reset     int64
 	}
 }
+
+func (e *RateLimitError) Unwrap() error {
+	return e.APIError
+}
+
+// MissingFilesError is returned when a deployment can't be created because files of its manifest haven't been
+// uploaded.
+type MissingFilesError struct {
+	*APIError
+	// Missing is the SHA1 digest of every file that needs to be uploaded.
+	Missing []string `json:"missing"`
+}
+
+func (e *MissingFilesError) Unwrap() error {
+	return e.APIError
+}
+
+// newAPIError will read the body of an error response and create an APIError from it. The body is left closed.
+func newAPIError(resp *http.Response) *APIError {
+	apiError := &APIError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("X-Now-Id"),
+	}
+	if apiError.RequestID == "" {
+		apiError.RequestID = resp.Header.Get("X-Request-Id")
+	}
+	if resp.Request != nil {
+		apiError.Method = resp.Request.Method
+		apiError.Endpoint = strings.TrimPrefix(resp.Request.URL.Path, "/")
+	}
+	if resp.Body != nil {
+		apiError.Body, _ = ioutil.ReadAll(resp.Body)
+	}
+	apiError.decodeDetail(apiError)
+	return apiError
+}
+
+// newGetError wraps the APIError returned when getting a domain fails.
+func newGetError(apiError *APIError) error {
+	getError := &GetError{APIError: apiError}
+	apiError.decodeDetail(getError)
+	return getError
+}
+
+// newVerificationError wraps the APIError returned when verifying a domain fails.
+func newVerificationError(apiError *APIError) error {
+	verificationError := &VerificationError{APIError: apiError}
+	apiError.decodeDetail(verificationError)
+	return verificationError
+}
+
+// newMissingFilesError wraps the APIError returned when creating a deployment fails, only errors listing missing files
+// are wrapped.
+func newMissingFilesError(apiError *APIError) error {
+	missingFilesError := &MissingFilesError{APIError: apiError}
+	apiError.decodeDetail(missingFilesError)
+	if apiError.Code != "missing_files" || len(missingFilesError.Missing) == 0 {
+		return wrapAPIError(apiError)
+	}
+	return missingFilesError
+}
+
+// errorFromResponse will create the error for a response with an error status. The APIError is passed to wrapError
+// if it is defined, otherwise it is wrapped in a more specific error where the status code has one.
+func errorFromResponse(resp *http.Response, wrapError func(*APIError) error) error {
+	apiError := newAPIError(resp)
+	if wrapError != nil {
+		return wrapError(apiError)
+	}
+	return wrapAPIError(apiError)
+}
+
+// wrapAPIError wraps the APIError in a more specific error where its status code has one.
+func wrapAPIError(apiError *APIError) error {
+	switch apiError.StatusCode {
+	case http.StatusConflict:
+		conflictError := &ConflictError{APIError: apiError}
+		apiError.decodeDetail(conflictError)
+		return conflictError
+	case http.StatusTooManyRequests:
+		rateLimitError := &RateLimitError{APIError: apiError}
+		apiError.decodeDetail(rateLimitError)
+		return rateLimitError
+	default:
+		return apiError
+	}
+}
@@ -0,0 +1,75 @@
+package zeit
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestAPIError_Is(t *testing.T) {
+	a := assert.New(t)
+
+	sentinels := map[int]error{
+		http.StatusNotFound:        ErrNotFound,
+		http.StatusConflict:        ErrConflict,
+		http.StatusForbidden:       ErrForbidden,
+		http.StatusTooManyRequests: ErrRateLimited,
+	}
+
+	for statusCode, sentinel := range sentinels {
+		t.Run(http.StatusText(statusCode), func(t *testing.T) {
+			err := error(&APIError{StatusCode: statusCode})
+			a.True(errors.Is(err, sentinel), "should match the sentinel for the status code")
+			for _, other := range sentinels {
+				if other != sentinel {
+					a.False(errors.Is(err, other), "should not match other sentinels")
+				}
+			}
+		})
+	}
+}
+
+func TestErrorFromResponse(t *testing.T) {
+	a := assert.New(t)
+
+	body := []byte(`{"error":{"code":"conflict_record","message":"A conflicting record exists","oldId":"rec_1"}}`)
+	resp := makeResponse(body, http.StatusConflict)
+	resp.Header.Set("X-Now-Id", "syd1::abcd")
+	resp.Request = &http.Request{Method: http.MethodPost, URL: &url.URL{Path: "/v2/domains/test.com/records"}}
+
+	err := errorFromResponse(&resp, nil)
+	a.True(errors.Is(err, ErrConflict), "should match ErrConflict")
+
+	var conflictError *ConflictError
+	a.True(errors.As(err, &conflictError), "should be a ConflictError")
+	a.Equal("rec_1", conflictError.OldId, "should decode the conflict detail")
+
+	var apiError *APIError
+	a.True(errors.As(err, &apiError), "should wrap an APIError")
+	a.Equal(http.StatusConflict, apiError.StatusCode)
+	a.Equal("conflict_record", apiError.Code)
+	a.Equal("A conflicting record exists", apiError.Message)
+	a.Equal("syd1::abcd", apiError.RequestID)
+	a.Equal(http.MethodPost, apiError.Method)
+	a.Equal("v2/domains/test.com/records", apiError.Endpoint)
+	a.Equal(body, apiError.Body)
+	a.Equal("POST v2/domains/test.com/records: 409 conflict_record: A conflicting record exists", err.Error())
+
+	var basicError *BasicError
+	a.True(errors.As(err, &basicError), "the deprecated BasicError should still match")
+	a.Equal("conflict_record", basicError.Code)
+}
+
+func TestErrorFromResponse_InvalidBody(t *testing.T) {
+	a := assert.New(t)
+
+	resp := makeResponse([]byte("<html>Bad Gateway</html>"), http.StatusBadGateway)
+	err := errorFromResponse(&resp, nil)
+
+	var apiError *APIError
+	a.True(errors.As(err, &apiError), "should still return an APIError")
+	a.Equal(http.StatusBadGateway, apiError.StatusCode)
+	a.Equal("502 Bad Gateway", err.Error())
+}
@@ -0,0 +1,240 @@
+package zeit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// maxEventSize is the longest line of the event stream that can be read, a line holds a single event.
+const maxEventSize = 1 << 20
+
+// DeploymentEventType is the kind of a build event of a deployment.
+type DeploymentEventType string
+
+const (
+	// DeploymentEventCommand is a command run by the build.
+	DeploymentEventCommand DeploymentEventType = "command"
+	// DeploymentEventStdout and DeploymentEventStderr are output written by the build.
+	DeploymentEventStdout DeploymentEventType = "stdout"
+	DeploymentEventStderr DeploymentEventType = "stderr"
+	// DeploymentEventExit is sent when a build step exits.
+	DeploymentEventExit DeploymentEventType = "exit"
+	// DeploymentEventState is sent when the state of the deployment changes.
+	DeploymentEventState DeploymentEventType = "deployment-state"
+)
+
+// DeploymentEvent is a single event of the build of a deployment.
+type DeploymentEvent struct {
+	Type    DeploymentEventType
+	Created *Time
+	// Text is the command or output, set for command, stdout and stderr events.
+	Text string
+	// State is the new state of the deployment, set for state events.
+	State DeploymentState
+	// Payload is the raw payload of the event, it holds any detail of the event not read into the other fields.
+	Payload json.RawMessage
+}
+
+// UnmarshalJSON reads an event of the stream, the text and state are read from its payload.
+func (e *DeploymentEvent) UnmarshalJSON(data []byte) error {
+	event := struct {
+		Type    DeploymentEventType `json:"type"`
+		Created *Time               `json:"created"`
+		Payload json.RawMessage     `json:"payload"`
+	}{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return err
+	}
+	payload := struct {
+		Text string `json:"text"`
+		Info struct {
+			ReadyState DeploymentState `json:"readyState"`
+		} `json:"info"`
+	}{}
+	if len(event.Payload) > 0 {
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return err
+		}
+	}
+	*e = DeploymentEvent{
+		Type:    event.Type,
+		Created: event.Created,
+		Text:    payload.Text,
+		State:   payload.Info.ReadyState,
+		Payload: event.Payload,
+	}
+	return nil
+}
+
+// DeploymentEventIterator follows the event stream of a deployment. If the connection drops the stream is requested
+// again from the time of the last event read, events already read are not repeated.
+type DeploymentEventIterator struct {
+	client Client
+	ctx    context.Context
+	id     string
+
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	event   DeploymentEvent
+	// last is the time of the newest event read in milliseconds and seen is how many events were read with that time,
+	// they are skipped when the stream is requested again.
+	last int64
+	seen int
+	skip int
+	// failures is the number of times in a row the stream ended without a new event being read.
+	failures int
+	finished bool
+	err      error
+}
+
+// StreamDeploymentEvents will follow the build events of the deployment until it is ready, has failed or is canceled.
+// The stream is bound to the context, cancelling it stops the iterator. Close should be called if the iterator isn't
+// read until Next returns false. The stream is a single long request, so it is cut short by WithTimeout. When the
+// connection drops it is made again, up to the MaxAttempts of the retry policy in a row without reading an event.
+func (c Client) StreamDeploymentEvents(ctx context.Context, id string) *DeploymentEventIterator {
+	return &DeploymentEventIterator{client: c, ctx: ctx, id: id}
+}
+
+// Next advances the iterator to the next event, waiting for it to be sent. It returns false once the deployment is
+// ready, has failed or is canceled, or an error occurred.
+func (it *DeploymentEventIterator) Next() bool {
+	for {
+		if it.finished || it.err != nil {
+			it.Close()
+			return false
+		}
+		if it.body == nil {
+			if it.err = it.connect(); it.err != nil {
+				return false
+			}
+		}
+
+		if it.scanner.Scan() {
+			if it.read(it.scanner.Bytes()) {
+				return true
+			}
+			continue
+		}
+
+		streamErr := it.scanner.Err()
+		it.Close()
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+		if streamErr == nil {
+			// the stream ended without the final state, find out whether the deployment has finished before following
+			// it again
+			deployment, err := it.client.GetDeploymentContext(it.ctx, it.id)
+			if err != nil {
+				it.err = err
+				return false
+			}
+			if isFinished(deployment.State) {
+				it.finished = true
+				return false
+			}
+		}
+
+		policy := it.client.retry()
+		it.failures++
+		if it.failures >= policy.MaxAttempts {
+			if streamErr == nil {
+				streamErr = errors.New("stream ended")
+			}
+			it.err = fmt.Errorf("following events of deployment %s: %w", it.id, streamErr)
+			return false
+		}
+		if it.err = sleepContext(it.ctx, policy.backoff(it.failures)); it.err != nil {
+			return false
+		}
+	}
+}
+
+// Value returns the current event.
+func (it *DeploymentEventIterator) Value() DeploymentEvent {
+	return it.event
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *DeploymentEventIterator) Err() error {
+	return it.err
+}
+
+// Close stops following the stream, it is safe to call more than once.
+func (it *DeploymentEventIterator) Close() {
+	if it.body != nil {
+		_ = it.body.Close()
+		it.body, it.scanner = nil, nil
+	}
+}
+
+// connect will request the event stream, starting from the time of the last event read.
+func (it *DeploymentEventIterator) connect() error {
+	query := url.Values{}
+	query.Set("follow", "1")
+	if it.last > 0 {
+		query.Set("since", strconv.FormatInt(it.last, 10))
+	}
+	endpoint := fmt.Sprintf("v2/now/deployments/%s/events?%s", it.id, query.Encode())
+	resp, err := it.client.makeAndDoRequest(it.ctx, http.MethodGet, endpoint, nil, nil)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		defer closeResponseBody(resp)
+		return errorFromResponse(resp, nil)
+	}
+	it.body = resp.Body
+	it.scanner = bufio.NewScanner(resp.Body)
+	it.scanner.Buffer(make([]byte, 0, 4096), maxEventSize)
+	it.skip = it.seen
+	return nil
+}
+
+// read will decode a line of the stream into the current event, it returns false if the line should be skipped.
+func (it *DeploymentEventIterator) read(line []byte) bool {
+	if len(line) == 0 {
+		return false
+	}
+	event := DeploymentEvent{}
+	if err := json.Unmarshal(line, &event); err != nil {
+		it.err = fmt.Errorf("reading event of deployment %s: %w", it.id, err)
+		return false
+	}
+
+	var created int64
+	if event.Created != nil {
+		created = event.Created.UnixNano() / int64(time.Millisecond)
+	}
+	switch {
+	case created < it.last:
+		return false
+	case created == it.last && it.skip > 0:
+		// the stream is requested again from the time of the last event, so events with that time are sent again
+		it.skip--
+		return false
+	case created > it.last:
+		it.last, it.seen, it.skip = created, 0, 0
+	}
+	it.seen++
+	it.failures = 0
+	it.event = event
+	if event.Type == DeploymentEventState && isFinished(event.State) {
+		it.finished = true
+	}
+	return true
+}
+
+// isFinished reports whether the deployment won't change state again.
+func isFinished(state DeploymentState) bool {
+	return state == DeploymentStateReady || state == DeploymentStateError || state == DeploymentStateCanceled
+}
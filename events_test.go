@@ -0,0 +1,99 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_StreamDeploymentEvents(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	added := server.AddDeployment("", zeittest.Deployment{Name: "web", State: "BUILDING"})
+	server.AddDeploymentEvent("", added.Id, zeittest.Event{Type: "command", Text: "npm run build"})
+	server.AddDeploymentEvent("", added.Id, zeittest.Event{Type: "stdout", Text: "building"})
+
+	var events []DeploymentEvent
+	it := client.StreamDeploymentEvents(context.Background(), added.Id)
+	for it.Next() {
+		event := it.Value()
+		events = append(events, event)
+		// events added while the stream is followed are sent as well
+		if event.Text == "building" {
+			server.AddDeploymentEvent("", added.Id, zeittest.Event{Type: "stderr", Text: "warning"})
+			server.SetDeploymentState("", added.Id, "READY", "", "")
+		}
+	}
+	a.Nil(it.Err(), "Error should be nil")
+
+	if a.Len(events, 4) {
+		a.Equal(DeploymentEventCommand, events[0].Type)
+		a.Equal("npm run build", events[0].Text)
+		a.Equal(DeploymentEventStdout, events[1].Type)
+		a.Equal(DeploymentEventStderr, events[2].Type)
+		a.Equal("warning", events[2].Text)
+		a.Equal(DeploymentEventState, events[3].Type)
+		a.Equal(DeploymentStateReady, events[3].State)
+		a.NotNil(events[3].Created)
+		a.NotEmpty(events[3].Payload)
+	}
+
+	// the stream of a finished deployment ends once every event has been sent
+	events = nil
+	it = client.StreamDeploymentEvents(context.Background(), added.Id)
+	for it.Next() {
+		events = append(events, it.Value())
+	}
+	a.Nil(it.Err(), "Error should be nil")
+	a.Len(events, 4)
+
+	it = client.StreamDeploymentEvents(context.Background(), "dpl_missing")
+	a.False(it.Next())
+	a.True(errors.Is(it.Err(), ErrNotFound))
+}
+
+func TestClient_StreamDeploymentEventsReconnect(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+
+	added := server.AddDeployment("", zeittest.Deployment{Name: "web", State: "BUILDING"})
+	for _, text := range []string{"one", "two", "three", "four", "five"} {
+		server.AddDeploymentEvent("", added.Id, zeittest.Event{Type: "stdout", Text: text})
+	}
+	// events with the same time are sent again when the stream is resumed from that time
+	for _, text := range []string{"six", "seven"} {
+		server.AddDeploymentEvent("", added.Id, zeittest.Event{Type: "stdout", Text: text, Created: added.Created + 100})
+	}
+	server.SetDeploymentState("", added.Id, "ERROR", "build_failed", "The build failed")
+	server.DropEventStreams(3)
+
+	var texts []string
+	var states []DeploymentState
+	it := client.StreamDeploymentEvents(context.Background(), added.Id)
+	for it.Next() {
+		if event := it.Value(); event.Type == DeploymentEventStdout {
+			texts = append(texts, event.Text)
+		} else {
+			states = append(states, event.State)
+		}
+	}
+	a.Nil(it.Err(), "Error should be nil")
+	a.Equal([]DeploymentState{DeploymentStateError}, states)
+	a.Equal([]string{"one", "two", "three", "four", "five", "six", "seven"}, texts,
+		"every event should be read once after reconnecting")
+
+	// a stream that can't make progress gives up
+	server.DropEventStreams(1)
+	it = client.StreamDeploymentEvents(context.Background(), added.Id)
+	for it.Next() {
+	}
+	a.Error(it.Err(), "should stop reconnecting")
+}
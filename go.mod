@@ -1,6 +1,6 @@
 module github.com/kochie/zeit-api-go
 
-go 1.12
+go 1.13
 
 require (
 	github.com/golang/mock v1.3.0
@@ -8,4 +8,5 @@ require (
 	github.com/stretchr/testify v1.3.0
 	golang.org/x/lint v0.0.0-20190409202823-959b441ac422 // indirect
 	golang.org/x/tools v0.0.0-20190511041617-99f201b6807e // indirect
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,180 @@
+// Package ignore matches paths against ignore patterns, such as those of .nowignore and .gitignore files, with the
+// semantics of gitignore.
+//
+//	matcher, err := ignore.New("node_modules/", "*.log", "!important.log", "/build/**/*.map")
+//	matcher.Match("logs/debug.log", false) // true
+//
+// A pattern without a slash matches at any depth, a pattern with a slash at the start or in the middle is anchored to
+// the root. A trailing slash matches only directories and a leading ! re-includes paths excluded by an earlier
+// pattern. * and ? match anything but a slash, **/ matches any number of directories and a trailing /** matches
+// everything inside a directory. The last pattern that matches a path decides whether it is ignored, and everything
+// inside an ignored directory is ignored.
+package ignore
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Matcher decides whether paths are ignored. The zero value ignores nothing.
+type Matcher struct {
+	patterns []pattern
+}
+
+type pattern struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// New will create a matcher from the patterns, see Add.
+func New(patterns ...string) (*Matcher, error) {
+	m := &Matcher{}
+	if err := m.Add(patterns...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Add will add the patterns after those already added, so they take precedence. Each pattern is a line of an ignore
+// file, blank lines and comments starting with # are skipped.
+func (m *Matcher) Add(patterns ...string) error {
+	for _, line := range patterns {
+		p, ok, err := parse(line)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", line, err)
+		}
+		if ok {
+			m.patterns = append(m.patterns, p)
+		}
+	}
+	return nil
+}
+
+// Read will add the patterns of every line read from r.
+func (m *Matcher) Read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		if err := m.Add(scanner.Text()); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// AddFile will add the patterns of the ignore file at path.
+func (m *Matcher) AddFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := m.Read(f); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// Match reports whether the path is ignored, isDir is whether the path is a directory. The path is relative to the
+// root the patterns are anchored to and separated by forward slashes. A path inside an ignored directory is always
+// ignored.
+func (m *Matcher) Match(path string, isDir bool) bool {
+	path = strings.Trim(path, "/")
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' && m.match(path[:i], true) {
+			return true
+		}
+	}
+	return m.match(path, isDir)
+}
+
+// match reports whether the last pattern matching the path excludes it, without considering its parents.
+func (m *Matcher) match(path string, isDir bool) bool {
+	ignored := false
+	for _, p := range m.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if p.re.MatchString(path) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// parse will compile a line of an ignore file, ok is false if the line doesn't hold a pattern.
+func parse(line string) (p pattern, ok bool, err error) {
+	line = trimTrailingSpace(line)
+	if line == "" || line[0] == '#' {
+		return p, false, nil
+	}
+	if line[0] == '!' {
+		p.negate = true
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return p, false, nil
+	}
+
+	// a slash anywhere but the end anchors the pattern to the root, otherwise it matches at any depth
+	prefix := "^(?:.*/)?"
+	if strings.Contains(line, "/") {
+		prefix = "^"
+		line = strings.TrimPrefix(line, "/")
+	}
+	p.re, err = regexp.Compile(prefix + translate(line) + "$")
+	return p, true, err
+}
+
+// translate converts a glob to a regular expression.
+func translate(glob string) string {
+	var re strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			re.WriteString("(?:.*/)?")
+			i += 2
+		case glob[i:] == "**" && i > 0 && glob[i-1] == '/':
+			re.WriteString(".*")
+			i++
+		case c == '*':
+			re.WriteString("[^/]*")
+		case c == '?':
+			re.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				re.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			re.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			re.WriteString(regexp.QuoteMeta(glob[i+1 : i+2]))
+			i++
+		default:
+			re.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return re.String()
+}
+
+// trimTrailingSpace removes trailing spaces from the line, unless they are escaped with a backslash.
+func trimTrailingSpace(line string) string {
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,80 @@
+package ignore
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher_Match(t *testing.T) {
+	tests := []struct {
+		patterns []string
+		path     string
+		isDir    bool
+		ignored  bool
+	}{
+		// unanchored patterns match at any depth
+		{[]string{"*.log"}, "debug.log", false, true},
+		{[]string{"*.log"}, "logs/debug.log", false, true},
+		{[]string{"*.log"}, "debug.txt", false, false},
+		{[]string{"debug?.log"}, "debug1.log", false, true},
+		{[]string{"debug[0-9].log"}, "debug10.log", false, false},
+		{[]string{"debug[!0-9].log"}, "debuga.log", false, true},
+		// a slash at the start or in the middle anchors the pattern
+		{[]string{"/build"}, "build", true, true},
+		{[]string{"/build"}, "src/build", true, false},
+		{[]string{"doc/*.txt"}, "doc/notes.txt", false, true},
+		{[]string{"doc/*.txt"}, "doc/server/arch.txt", false, false},
+		{[]string{"doc/*.txt"}, "src/doc/notes.txt", false, false},
+		// a trailing slash only matches directories, and everything inside them
+		{[]string{"logs/"}, "logs", false, false},
+		{[]string{"logs/"}, "logs", true, true},
+		{[]string{"logs/"}, "app/logs/today.txt", false, true},
+		// double asterisks
+		{[]string{"**/cache"}, "cache", true, true},
+		{[]string{"**/cache"}, "a/b/cache", true, true},
+		{[]string{"a/**/b"}, "a/b", false, true},
+		{[]string{"a/**/b"}, "a/x/y/b", false, true},
+		{[]string{"static/**"}, "static/css/site.css", false, true},
+		{[]string{"static/**"}, "static", true, false},
+		{[]string{"**/*.map"}, "dist/js/app.js.map", false, true},
+		// the last matching pattern wins
+		{[]string{"*.log", "!important.log"}, "important.log", false, false},
+		{[]string{"!important.log", "*.log"}, "important.log", false, true},
+		// a file can't be re-included if its directory is ignored
+		{[]string{"logs/", "!logs/important.log"}, "logs/important.log", false, true},
+		{[]string{"logs/*", "!logs/important.log"}, "logs/important.log", false, false},
+		// comments, blank lines and escapes
+		{[]string{"# comment", "", "   "}, "# comment", false, false},
+		{[]string{`\#hash`}, "#hash", false, true},
+		{[]string{`\!bang`}, "!bang", false, true},
+		{[]string{"trailing   "}, "trailing", false, true},
+		{[]string{`space\ `}, "space ", false, true},
+		{[]string{"a.c"}, "abc", false, false},
+		{[]string{"[unterminated"}, "[unterminated", false, true},
+	}
+
+	for _, test := range tests {
+		m, err := New(test.patterns...)
+		if !assert.Nil(t, err, "%q should be valid", test.patterns) {
+			continue
+		}
+		assert.Equal(t, test.ignored, m.Match(test.path, test.isDir), "%q matching %q", test.patterns, test.path)
+	}
+}
+
+func TestMatcher_Read(t *testing.T) {
+	a := assert.New(t)
+
+	m := &Matcher{}
+	a.False(m.Match("anything", false), "the zero value should ignore nothing")
+
+	a.Nil(m.Read(strings.NewReader("# build output\ndist/\n*.tmp\n!keep.tmp\n")))
+	a.True(m.Match("dist/index.html", false))
+	a.True(m.Match("a.tmp", false))
+	a.False(m.Match("keep.tmp", false))
+
+	err := m.Read(strings.NewReader("ok\n[z-a]\n"))
+	a.EqualError(err, `line 2: invalid pattern "[z-a]": error parsing regexp: invalid character class range: `+"`z-a`")
+}
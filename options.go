@@ -0,0 +1,61 @@
+package zeit
+
+import (
+	"strings"
+	"time"
+)
+
+// Option configures a Client when it is created with NewClient.
+type Option func(*Client)
+
+// WithBaseURL will send every request to the given url instead of the public ZEIT API, this is useful for pointing the
+// client at a local test server. Any trailing slash is removed.
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.rootUrl = strings.TrimSuffix(url, "/")
+	}
+}
+
+// WithHTTPClient will use the given HttpClient to send requests, for example an *http.Client with a custom transport.
+func WithHTTPClient(httpClient HttpClient) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTeam will scope every request made by the client to the given team id.
+func WithTeam(team string) Option {
+	return func(c *Client) {
+		c.team = team
+	}
+}
+
+// WithUserAgent will set the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) Option {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithTimeout will limit how long a single http request can take. The timeout is only applied when the underlying
+// HttpClient is an *http.Client, the client is copied so the original is left unchanged. Use a context deadline to
+// limit other HttpClient implementations.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRateLimiter will consult the given RateLimiter before every request instead of the default HeaderRateLimiter.
+func WithRateLimiter(rateLimiter RateLimiter) Option {
+	return func(c *Client) {
+		c.rateLimiter = rateLimiter
+	}
+}
+
+// WithRetryPolicy will retry failed requests according to the given policy instead of DefaultRetryPolicy.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retryPolicy = policy
+	}
+}
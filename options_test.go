@@ -0,0 +1,55 @@
+package zeit
+
+import (
+	"github.com/golang/mock/gomock"
+	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewClient_Defaults(t *testing.T) {
+	a := assert.New(t)
+	client := NewClient(TestToken)
+
+	a.Equal(defaultRootUrl, client.rootUrl, "should use the public api by default")
+	a.IsType(&http.Client{}, client.httpClient, "should use a http client by default")
+	a.Empty(client.team, "team should be empty by default")
+}
+
+func TestNewClient_Options(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := NewClient(TestToken,
+		WithBaseURL("http://localhost:8080/"),
+		WithHTTPClient(mockHttpClient),
+		WithTeam("team_123"),
+		WithUserAgent("zeit-test/1.0"),
+	)
+
+	httpResponse := makeResponse([]byte(`{"domains":[]}`), http.StatusOK)
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		a.Equal("localhost:8080", req.URL.Host, "should use the base url")
+		a.Equal("/v4/domains", req.URL.Path, "should join the endpoint to the base url")
+		a.Equal("team_123", req.URL.Query().Get("teamId"), "should scope the request to the team")
+		a.Equal("zeit-test/1.0", req.Header.Get("User-Agent"), "should set the user agent")
+		return &httpResponse, nil
+	})
+
+	_, err := client.ListAllDomains()
+	a.Nil(err, "Error should be nil")
+}
+
+func TestWithTimeout(t *testing.T) {
+	a := assert.New(t)
+
+	httpClient := &http.Client{}
+	client := NewClient(TestToken, WithHTTPClient(httpClient), WithTimeout(time.Second))
+
+	a.Equal(time.Second, client.httpClient.(*http.Client).Timeout, "timeout should be applied")
+	a.Equal(time.Duration(0), httpClient.Timeout, "original http client should not be changed")
+}
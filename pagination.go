@@ -0,0 +1,236 @@
+package zeit
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Pagination is sent by the API with every page of a list endpoint. Next and Prev are unix timestamps in milliseconds,
+// Next is zero on the last page.
+type Pagination struct {
+	Count int   `json:"count"`
+	Next  int64 `json:"next"`
+	Prev  int64 `json:"prev"`
+}
+
+// ListOption changes which items are returned by a list endpoint.
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	limit   int
+	since   time.Time
+	until   time.Time
+	filters url.Values
+}
+
+// WithPageSize will request at most limit items per page.
+func WithPageSize(limit int) ListOption {
+	return func(o *listOptions) {
+		o.limit = limit
+	}
+}
+
+// WithSince will only list items created after t.
+func WithSince(t time.Time) ListOption {
+	return func(o *listOptions) {
+		o.since = t
+	}
+}
+
+// WithUntil will only list items created before t.
+func WithUntil(t time.Time) ListOption {
+	return func(o *listOptions) {
+		o.until = t
+	}
+}
+
+// WithProject will only list deployments of the project, it is used by ListDeployments.
+func WithProject(projectId string) ListOption {
+	return func(o *listOptions) {
+		o.filter("projectId", projectId)
+	}
+}
+
+// WithDeploymentState will only list deployments in one of the states, it is used by ListDeployments.
+func WithDeploymentState(states ...DeploymentState) ListOption {
+	return func(o *listOptions) {
+		values := make([]string, len(states))
+		for i, state := range states {
+			values[i] = string(state)
+		}
+		o.filter("state", strings.Join(values, ","))
+	}
+}
+
+// filter adds a query parameter that only some list endpoints understand.
+func (o *listOptions) filter(key, value string) {
+	if o.filters == nil {
+		o.filters = url.Values{}
+	}
+	o.filters.Set(key, value)
+}
+
+// unixMilli returns t as a unix timestamp in milliseconds, the format the API uses for time filters.
+func unixMilli(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// pager follows the pagination cursors of a list endpoint, each page is requested with until set to the next cursor
+// of the previous page.
+type pager struct {
+	client   Client
+	endpoint string
+	query    url.Values
+	until    int64
+	done     bool
+	err      error
+}
+
+func newPager(c Client, endpoint string, opts []ListOption) *pager {
+	options := listOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	query := url.Values{}
+	for key, values := range options.filters {
+		query[key] = values
+	}
+	if options.limit > 0 {
+		query.Set("limit", strconv.Itoa(options.limit))
+	}
+	if !options.since.IsZero() {
+		query.Set("since", strconv.FormatInt(unixMilli(options.since), 10))
+	}
+	p := &pager{client: c, endpoint: endpoint, query: query}
+	if !options.until.IsZero() {
+		p.until = unixMilli(options.until)
+	}
+	return p
+}
+
+// fetch will request the next page and decode it into result, the pagination of the response must be decoded into
+// pagination. It returns false if there are no more pages or the request failed.
+func (p *pager) fetch(ctx context.Context, result interface{}, pagination *Pagination) bool {
+	if p.done {
+		return false
+	}
+	query := url.Values{}
+	for key, values := range p.query {
+		query[key] = values
+	}
+	if p.until > 0 {
+		query.Set("until", strconv.FormatInt(p.until, 10))
+	}
+
+	p.err = p.client.do(ctx, request{
+		method:   http.MethodGet,
+		endpoint: p.endpoint,
+		query:    query,
+		result:   result,
+	})
+	if p.err != nil {
+		p.done = true
+		return false
+	}
+
+	// stop on the last page, or if the cursor doesn't move backwards so a misbehaving response can't loop forever
+	if pagination.Next == 0 || pagination.Count == 0 || (p.until > 0 && pagination.Next >= p.until) {
+		p.done = true
+	} else {
+		p.until = pagination.Next
+	}
+	return true
+}
+
+// DomainIterator lazily lists domains, requesting a new page from the API only once every domain on the previous page
+// has been read.
+//
+//	it := client.IterateDomains()
+//	for it.Next(ctx) {
+//		domain := it.Value()
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type DomainIterator struct {
+	pager *pager
+	page  []Domain
+	index int
+}
+
+// IterateDomains will return an iterator over every domain registered with the user.
+func (c Client) IterateDomains(opts ...ListOption) *DomainIterator {
+	return &DomainIterator{pager: newPager(c, "v4/domains", opts), index: -1}
+}
+
+// Next advances the iterator to the next domain, it returns false when there are no more domains or an error occurred.
+func (it *DomainIterator) Next(ctx context.Context) bool {
+	for it.index+1 >= len(it.page) {
+		it.page, it.index = nil, -1
+		pagination := Pagination{}
+		result := &struct {
+			Domains    *[]Domain   `json:"domains"`
+			Pagination *Pagination `json:"pagination"`
+		}{&it.page, &pagination}
+		if !it.pager.fetch(ctx, result, &pagination) {
+			return false
+		}
+	}
+	it.index++
+	return true
+}
+
+// Value returns the current domain.
+func (it *DomainIterator) Value() Domain {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *DomainIterator) Err() error {
+	return it.pager.err
+}
+
+// RecordIterator lazily lists the DNS records of a domain, requesting a new page from the API only once every record on
+// the previous page has been read.
+type RecordIterator struct {
+	pager *pager
+	page  []Record
+	index int
+}
+
+// IterateDNSRecords will return an iterator over every DNS record of the domain.
+func (c Client) IterateDNSRecords(domain string, opts ...ListOption) *RecordIterator {
+	endpoint := "v4/domains/" + domain + "/records"
+	return &RecordIterator{pager: newPager(c, endpoint, opts), index: -1}
+}
+
+// Next advances the iterator to the next record, it returns false when there are no more records or an error occurred.
+func (it *RecordIterator) Next(ctx context.Context) bool {
+	for it.index+1 >= len(it.page) {
+		it.page, it.index = nil, -1
+		pagination := Pagination{}
+		result := &struct {
+			Records    *[]Record   `json:"records"`
+			Pagination *Pagination `json:"pagination"`
+		}{&it.page, &pagination}
+		if !it.pager.fetch(ctx, result, &pagination) {
+			return false
+		}
+	}
+	it.index++
+	return true
+}
+
+// Value returns the current record.
+func (it *RecordIterator) Value() Record {
+	return it.page[it.index]
+}
+
+// Err returns the error that stopped the iteration, if any.
+func (it *RecordIterator) Err() error {
+	return it.pager.err
+}
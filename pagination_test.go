@@ -0,0 +1,148 @@
+package zeit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// pageResponse creates the response for a page of records with the next cursor.
+func pageResponse(records []Record, next int64) *http.Response {
+	response, _ := json.Marshal(map[string]interface{}{
+		"records":    records,
+		"pagination": Pagination{Count: len(records), Next: next},
+	})
+	httpResponse := makeResponse(response, http.StatusOK)
+	return &httpResponse
+}
+
+func TestClient_ListDNSRecords_Pages(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	pages := map[string]*http.Response{
+		"":     pageResponse([]Record{{Id: "1"}, {Id: "2"}}, 2000),
+		"2000": pageResponse([]Record{{Id: "3"}, {Id: "4"}}, 1000),
+		"1000": pageResponse([]Record{{Id: "5"}}, 0),
+	}
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		a.Equal("/v4/domains/test.com/records", req.URL.Path)
+		a.Equal("2", req.URL.Query().Get("limit"), "page size should be sent")
+		a.Equal("500", req.URL.Query().Get("since"), "since should be sent")
+		return pages[req.URL.Query().Get("until")], nil
+	}).Times(3)
+
+	records, err := client.ListDNSRecords("test.com", WithPageSize(2), WithSince(time.Unix(0, 500*1e6)))
+	a.Nil(err, "Error should be nil")
+
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.Id)
+	}
+	a.Equal([]string{"1", "2", "3", "4", "5"}, ids, "every page should be collected")
+}
+
+func TestClient_ListDNSRecords_Endpoint(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		a.Equal(http.MethodGet, req.Method)
+		a.Equal("/v4/domains/test.com/records", req.URL.Path, "records should be listed with the v4 endpoint")
+		a.Empty(req.URL.Query().Get("until"), "the first page should be requested without a cursor")
+		return pageResponse([]Record{{Id: "1"}}, 0), nil
+	})
+
+	records, err := client.ListDNSRecords("test.com")
+	a.Nil(err, "Error should be nil")
+	a.Len(records, 1)
+}
+
+func TestRecordIterator(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:       TestToken,
+		rootUrl:     rootUrl,
+		httpClient:  mockHttpClient,
+		rateLimit:   &rateLimit{},
+		retryPolicy: RetryPolicy{MaxAttempts: 1},
+	}
+	ctx := context.Background()
+
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		a.Equal(fmt.Sprint(unixMilli(time.Unix(100, 0))), req.URL.Query().Get("until"), "until should be sent")
+		return pageResponse([]Record{{Id: "1"}, {Id: "2"}}, 2000), nil
+	})
+
+	it := client.IterateDNSRecords("test.com", WithUntil(time.Unix(100, 0)))
+	a.True(it.Next(ctx))
+	a.Equal("1", it.Value().Id)
+	a.True(it.Next(ctx), "second record should come from the same page")
+	a.Equal("2", it.Value().Id)
+
+	httpResponse := makeResponse([]byte(`{"error":{"code":"internal"}}`), http.StatusInternalServerError)
+	mockHttpClient.EXPECT().Do(gomock.Any()).Return(&httpResponse, nil)
+
+	a.False(it.Next(ctx), "should stop when a page fails")
+	var apiError *APIError
+	a.True(errors.As(it.Err(), &apiError), "should return the error")
+	a.False(it.Next(ctx), "should stay stopped")
+}
+
+func TestDomainIterator(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	first := makeResponse([]byte(`{"domains":[{"name":"a.com"}],"pagination":{"count":1,"next":10}}`), http.StatusOK)
+	second := makeResponse([]byte(`{"domains":[],"pagination":{"count":0,"next":null}}`), http.StatusOK)
+	gomock.InOrder(
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&first, nil),
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&second, nil),
+	)
+
+	it := client.IterateDomains()
+	var names []string
+	for it.Next(context.Background()) {
+		names = append(names, it.Value().Name)
+	}
+	a.Nil(it.Err(), "Error should be nil")
+	a.Equal([]string{"a.com"}, names)
+}
@@ -0,0 +1,102 @@
+package zeit
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimitState is the most recent rate limit reported by the ZEIT API for a token.
+type RateLimitState struct {
+	// Limit is the total number of requests allowed in the current window.
+	Limit int
+	// Remaining is the number of requests that can still be sent before the window resets.
+	Remaining int
+	// Reset is the time at which the current window ends.
+	Reset time.Time
+}
+
+// rateLimit tracks the rate limit of a single token. It is shared between every Client using that token and is safe
+// for concurrent use.
+type rateLimit struct {
+	limit     int
+	remaining int
+	reset     time.Time
+	mutex     sync.Mutex
+}
+
+// rateLimits holds the shared rate limit of every token a client has been created for.
+var rateLimits = struct {
+	sync.Mutex
+	tokens map[string]*rateLimit
+}{tokens: make(map[string]*rateLimit)}
+
+// sharedRateLimit will return the rate limit associated with the token, creating it if this is the first time the
+// token has been seen.
+func sharedRateLimit(token string) *rateLimit {
+	rateLimits.Lock()
+	defer rateLimits.Unlock()
+	rl, ok := rateLimits.tokens[token]
+	if !ok {
+		rl = &rateLimit{limit: 1, remaining: 1, reset: time.Now()}
+		rateLimits.tokens[token] = rl
+	}
+	return rl
+}
+
+// state returns a copy of the current rate limit.
+func (rl *rateLimit) state() RateLimitState {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	return RateLimitState{Limit: rl.limit, Remaining: rl.remaining, Reset: rl.reset}
+}
+
+// reserve returns the current rate limit and takes one of the remaining requests, so concurrent callers don't all
+// see the last request in a window as available.
+func (rl *rateLimit) reserve() RateLimitState {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	state := RateLimitState{Limit: rl.limit, Remaining: rl.remaining, Reset: rl.reset}
+	if rl.remaining > 0 {
+		rl.remaining--
+	}
+	return state
+}
+
+// updateFromHeaders will record the rate limit sent in the X-RateLimit-* headers of a response. Headers that are
+// missing or can't be parsed are ignored.
+func (rl *rateLimit) updateFromHeaders(header http.Header) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	if rateLimitRemaining := header.Get("X-RateLimit-Remaining"); rateLimitRemaining != "" {
+		if remaining, err := strconv.ParseInt(rateLimitRemaining, 10, 32); err == nil {
+			rl.remaining = int(remaining)
+		}
+	}
+	if rateLimitLimit := header.Get("X-RateLimit-Limit"); rateLimitLimit != "" {
+		if limit, err := strconv.ParseInt(rateLimitLimit, 10, 32); err == nil {
+			rl.limit = int(limit)
+		}
+	}
+	if rateLimitReset := header.Get("X-RateLimit-Reset"); rateLimitReset != "" {
+		if reset, err := strconv.ParseInt(rateLimitReset, 10, 64); err == nil {
+			rl.reset = time.Unix(reset, 0)
+		}
+	}
+}
+
+// updateFromError will record the rate limit sent in the body of a 429 response.
+func (rl *rateLimit) updateFromError(rateLimitError RateLimitError) {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+	rl.remaining = rateLimitError.Limit.Remaining
+	rl.reset = time.Unix(rateLimitError.Limit.Reset, 0)
+	rl.limit = rateLimitError.Limit.Total
+}
+
+// RateLimit returns the most recent rate limit reported by the API for the token used by the client. The state is
+// shared by every client created with the same token.
+func (c Client) RateLimit() RateLimitState {
+	return c.rateLimit.state()
+}
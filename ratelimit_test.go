@@ -0,0 +1,106 @@
+package zeit
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSharedRateLimit(t *testing.T) {
+	a := assert.New(t)
+
+	first := NewClient("shared-token")
+	second := NewClient("shared-token")
+	other := NewClient("other-token")
+
+	a.True(first.rateLimit == second.rateLimit, "clients with the same token should share a rate limit")
+	a.False(first.rateLimit == other.rateLimit, "clients with different tokens should not share a rate limit")
+}
+
+func TestRateLimit_UpdateFromHeaders(t *testing.T) {
+	a := assert.New(t)
+
+	rl := &rateLimit{}
+	header := http.Header{}
+	header.Set("X-RateLimit-Remaining", "7")
+	header.Set("X-RateLimit-Limit", "10")
+	header.Set("X-RateLimit-Reset", "1558000000")
+	rl.updateFromHeaders(header)
+
+	a.Equal(RateLimitState{Limit: 10, Remaining: 7, Reset: time.Unix(1558000000, 0)}, rl.state())
+
+	header.Set("X-RateLimit-Remaining", "not a number")
+	rl.updateFromHeaders(header)
+	a.Equal(7, rl.state().Remaining, "invalid headers should be ignored")
+}
+
+func TestRateLimit_Reserve(t *testing.T) {
+	a := assert.New(t)
+
+	reset := time.Now().Add(time.Hour)
+	rl := &rateLimit{limit: 10, remaining: 2, reset: reset}
+	a.Equal(RateLimitState{Limit: 10, Remaining: 2, Reset: reset}, rl.reserve())
+	a.Equal(RateLimitState{Limit: 10, Remaining: 1, Reset: reset}, rl.reserve())
+	a.Equal(RateLimitState{Limit: 10, Remaining: 0, Reset: reset}, rl.reserve())
+	a.Equal(0, rl.state().Remaining, "remaining requests should not go below zero")
+}
+
+func TestRateLimit_Concurrent(t *testing.T) {
+	a := assert.New(t)
+
+	rl := &rateLimit{limit: 100, remaining: 50, reset: time.Now().Add(time.Hour)}
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	allowed := 0
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rl.reserve().Remaining > 0 {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	a.Equal(50, allowed, "only the remaining requests should be allowed")
+}
+
+func TestClient_TooManyRequests(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	reset := time.Now().Add(-time.Second).Unix()
+	tooManyRequests := makeResponse([]byte(fmt.Sprintf(
+		`{"error":{"code":"rate_limited","message":"Rate limit exceeded","limit":{"remaining":0,"reset":%d,"total":20}}}`,
+		reset,
+	)), http.StatusTooManyRequests)
+	ok := makeResponse([]byte(`{"domains":[]}`), http.StatusOK)
+
+	gomock.InOrder(
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&tooManyRequests, nil),
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&ok, nil),
+	)
+
+	_, err := client.ListAllDomains()
+	a.Nil(err, "should retry after the rate limit resets")
+	a.Equal(RateLimitState{Limit: 10, Remaining: 10, Reset: time.Unix(0, 0)}, client.RateLimit(),
+		"should record the rate limit headers of the final response")
+}
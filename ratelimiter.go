@@ -0,0 +1,140 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited is returned when a request can't be sent because the rate limit has been reached. Use errors.Is to
+// check for it and errors.As with *RateLimitedError to find out when the limit resets.
+var ErrRateLimited = errors.New("zeit rate limit reached")
+
+// RateLimitedError is returned by FailFastRateLimiter instead of waiting for the rate limit to reset.
+type RateLimitedError struct {
+	Limit int
+	Reset time.Time
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("%s, resets at %s", ErrRateLimited.Error(), e.Reset.Format(time.RFC3339))
+}
+
+// Is reports whether the target is ErrRateLimited.
+func (e *RateLimitedError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RateLimiter decides whether a request can be sent. Wait is called before every request with the latest rate limit
+// reported by the API for the token, it should return nil once the request can be sent or an error to abort the request.
+// Implementations must be safe for concurrent use.
+type RateLimiter interface {
+	Wait(ctx context.Context, state RateLimitState) error
+}
+
+// HeaderRateLimiter waits until the rate limit reported by the API resets whenever there are no requests remaining. This
+// is the default rate limiter, it suits batch jobs that would rather wait than fail.
+type HeaderRateLimiter struct {
+	// OnWait is called with the time the limiter is about to wait for, it can be used for logging.
+	OnWait func(d time.Duration)
+}
+
+// Wait will block until the rate limit resets if no requests remain, or until the context is done.
+func (l *HeaderRateLimiter) Wait(ctx context.Context, state RateLimitState) error {
+	now := time.Now()
+	if state.Remaining > 0 || !now.Before(state.Reset) {
+		return nil
+	}
+	d := state.Reset.Sub(now)
+	if l.OnWait != nil {
+		l.OnWait(d)
+	}
+	return sleepContext(ctx, d)
+}
+
+// FailFastRateLimiter returns a *RateLimitedError straight away when no requests remain instead of waiting for the rate
+// limit to reset. It suits latency sensitive callers such as API handlers.
+type FailFastRateLimiter struct{}
+
+// Wait will return a *RateLimitedError if no requests remain, otherwise nil.
+func (FailFastRateLimiter) Wait(ctx context.Context, state RateLimitState) error {
+	if state.Remaining > 0 || !time.Now().Before(state.Reset) {
+		return nil
+	}
+	return &RateLimitedError{Limit: state.Limit, Reset: state.Reset}
+}
+
+// TokenBucketRateLimiter caps the rate requests are sent at, regardless of the limit reported by the API. Tokens are
+// added to the bucket at Rate per second up to Burst and each request takes one token, waiting for it if the bucket is
+// empty.
+type TokenBucketRateLimiter struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	mutex  sync.Mutex
+}
+
+// NewTokenBucketRateLimiter will create a token bucket allowing rate requests per second with bursts of up to burst
+// requests. The bucket starts full. It panics if rate isn't positive, as no requests could ever be allowed.
+func NewTokenBucketRateLimiter(rate float64, burst int) *TokenBucketRateLimiter {
+	if !(rate > 0) {
+		panic(fmt.Sprintf("zeit: token bucket rate must be positive, got %v", rate))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucketRateLimiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Wait will take a token from the bucket, blocking until one is available or the context is done.
+func (l *TokenBucketRateLimiter) Wait(ctx context.Context, state RateLimitState) error {
+	l.mutex.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	l.tokens--
+	tokens := l.tokens
+	l.mutex.Unlock()
+
+	if tokens >= 0 {
+		return nil
+	}
+	d := time.Duration(-tokens / l.rate * float64(time.Second))
+	if err := sleepContext(ctx, d); err != nil {
+		// the request won't be sent so give the token back
+		l.mutex.Lock()
+		l.tokens++
+		l.mutex.Unlock()
+		return err
+	}
+	return nil
+}
+
+// ChainRateLimiters will combine several rate limiters, a request is only sent once every one of them allows it. For
+// example a TokenBucketRateLimiter can be chained with a HeaderRateLimiter to cap the request rate while still
+// respecting the limit reported by the API.
+func ChainRateLimiters(rateLimiters ...RateLimiter) RateLimiter {
+	return rateLimiterChain(rateLimiters)
+}
+
+type rateLimiterChain []RateLimiter
+
+func (chain rateLimiterChain) Wait(ctx context.Context, state RateLimitState) error {
+	for _, rateLimiter := range chain {
+		if err := rateLimiter.Wait(ctx, state); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestHeaderRateLimiter(t *testing.T) {
+	a := assert.New(t)
+
+	var waited time.Duration
+	limiter := &HeaderRateLimiter{OnWait: func(d time.Duration) {
+		waited = d
+	}}
+
+	a.Nil(limiter.Wait(context.Background(), RateLimitState{Remaining: 1, Reset: time.Now().Add(time.Hour)}))
+	a.Nil(limiter.Wait(context.Background(), RateLimitState{Remaining: 0, Reset: time.Now().Add(-time.Hour)}))
+	a.Zero(waited, "should not wait while requests remain")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := limiter.Wait(ctx, RateLimitState{Remaining: 0, Reset: time.Now().Add(time.Hour)})
+	a.Equal(context.Canceled, err, "should stop waiting when the context is done")
+	a.True(waited > 0, "should report the wait")
+}
+
+func TestFailFastRateLimiter(t *testing.T) {
+	a := assert.New(t)
+
+	limiter := FailFastRateLimiter{}
+	a.Nil(limiter.Wait(context.Background(), RateLimitState{Remaining: 1, Reset: time.Now().Add(time.Hour)}))
+
+	reset := time.Now().Add(time.Hour)
+	err := limiter.Wait(context.Background(), RateLimitState{Limit: 10, Remaining: 0, Reset: reset})
+	a.True(errors.Is(err, ErrRateLimited), "should fail with ErrRateLimited")
+
+	var rateLimitedError *RateLimitedError
+	a.True(errors.As(err, &rateLimitedError), "should fail with a RateLimitedError")
+	a.Equal(reset, rateLimitedError.Reset, "should report when the limit resets")
+}
+
+func TestTokenBucketRateLimiter(t *testing.T) {
+	a := assert.New(t)
+
+	limiter := NewTokenBucketRateLimiter(100, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		a.Nil(limiter.Wait(context.Background(), RateLimitState{}))
+	}
+	a.True(time.Since(start) >= 15*time.Millisecond, "should wait once the burst is used")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	a.Equal(context.Canceled, limiter.Wait(ctx, RateLimitState{}), "should stop waiting when the context is done")
+
+	a.Panics(func() { NewTokenBucketRateLimiter(0, 1) }, "a zero rate should be rejected")
+	a.Panics(func() { NewTokenBucketRateLimiter(-1, 1) }, "a negative rate should be rejected")
+}
+
+func TestChainRateLimiters(t *testing.T) {
+	a := assert.New(t)
+
+	limiter := ChainRateLimiters(NewTokenBucketRateLimiter(10, 1), FailFastRateLimiter{})
+	a.Nil(limiter.Wait(context.Background(), RateLimitState{Remaining: 1}))
+
+	err := limiter.Wait(context.Background(), RateLimitState{Remaining: 0, Reset: time.Now().Add(time.Hour)})
+	a.True(errors.Is(err, ErrRateLimited), "every rate limiter should be consulted")
+}
+
+func TestClient_FailFast(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:       TestToken,
+		rootUrl:     rootUrl,
+		httpClient:  mockHttpClient,
+		rateLimit:   &rateLimit{limit: 10, remaining: 0, reset: time.Now().Add(time.Hour)},
+		rateLimiter: FailFastRateLimiter{},
+	}
+
+	_, err := client.ListAllDomains()
+	a.True(errors.Is(err, ErrRateLimited), "should not send the request")
+}
@@ -2,6 +2,8 @@ package zeit
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 const (
@@ -24,6 +26,7 @@ type Record struct {
 	Creator     string
 	Created     *Time
 	Updated     *Time
+	TTL         int    `json:"ttl,omitempty"`
 	MxPriority  string `json:"mxPriority,omitempty"`
 	SrvPriority string `json:"priority,omitempty"`
 }
@@ -42,3 +45,210 @@ func (r *Record) GetValue() string {
 		return r.Value
 	}
 }
+
+// MXValue is the value of an MX record.
+type MXValue struct {
+	Priority int
+	Host     string
+}
+
+// ParseMXValue parses the wire format of an MX record, "<priority> <host>".
+func ParseMXValue(value string) (MXValue, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 2 {
+		return MXValue{}, fmt.Errorf("invalid MX value %q, expected \"<priority> <host>\"", value)
+	}
+	priority, err := parseUint16("MX priority", fields[0])
+	if err != nil {
+		return MXValue{}, err
+	}
+	return MXValue{Priority: priority, Host: fields[1]}, nil
+}
+
+func (v MXValue) String() string {
+	return fmt.Sprintf("%d %s", v.Priority, v.Host)
+}
+
+// SRVValue is the value of an SRV record.
+type SRVValue struct {
+	Priority int
+	Weight   int
+	Port     int
+	Target   string
+}
+
+// ParseSRVValue parses the wire format of an SRV record, "<priority> <weight> <port> <target>".
+func ParseSRVValue(value string) (SRVValue, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 4 {
+		return SRVValue{}, fmt.Errorf("invalid SRV value %q, expected \"<priority> <weight> <port> <target>\"", value)
+	}
+	srv := SRVValue{Target: fields[3]}
+	var err error
+	if srv.Priority, err = parseUint16("SRV priority", fields[0]); err != nil {
+		return SRVValue{}, err
+	}
+	if srv.Weight, err = parseUint16("SRV weight", fields[1]); err != nil {
+		return SRVValue{}, err
+	}
+	if srv.Port, err = parseUint16("SRV port", fields[2]); err != nil {
+		return SRVValue{}, err
+	}
+	return srv, nil
+}
+
+func (v SRVValue) String() string {
+	return fmt.Sprintf("%d %d %d %s", v.Priority, v.Weight, v.Port, v.Target)
+}
+
+// CAAValue is the value of a CAA record.
+type CAAValue struct {
+	Flags int
+	Tag   string
+	Value string
+}
+
+// ParseCAAValue parses the wire format of a CAA record, `<flags> <tag> "<value>"`. The quotes around the value are
+// optional.
+func ParseCAAValue(value string) (CAAValue, error) {
+	fields := strings.SplitN(strings.TrimSpace(value), " ", 3)
+	if len(fields) != 3 {
+		return CAAValue{}, fmt.Errorf("invalid CAA value %q, expected `<flags> <tag> \"<value>\"`", value)
+	}
+	flags, err := strconv.ParseUint(fields[0], 10, 8)
+	if err != nil {
+		return CAAValue{}, fmt.Errorf("invalid CAA flags %q", fields[0])
+	}
+	caaValue := strings.TrimSpace(fields[2])
+	if unquoted, err := strconv.Unquote(caaValue); err == nil {
+		caaValue = unquoted
+	}
+	return CAAValue{Flags: int(flags), Tag: fields[1], Value: caaValue}, nil
+}
+
+func (v CAAValue) String() string {
+	return fmt.Sprintf("%d %s %s", v.Flags, v.Tag, strconv.Quote(v.Value))
+}
+
+// TXTValue is the value of a TXT record.
+type TXTValue struct {
+	Text string
+}
+
+// ParseTXTValue parses the value of a TXT record. The API stores the text as is, but a value made of quoted strings,
+// as written in a zone file, is joined into a single string.
+func ParseTXTValue(value string) (TXTValue, error) {
+	trimmed := strings.TrimSpace(value)
+	if !strings.HasPrefix(trimmed, `"`) {
+		return TXTValue{Text: value}, nil
+	}
+	var text strings.Builder
+	for trimmed != "" {
+		quoted := quotedPrefix(trimmed)
+		unquoted, err := strconv.Unquote(quoted)
+		if err != nil {
+			return TXTValue{}, fmt.Errorf("invalid TXT value %q", value)
+		}
+		text.WriteString(unquoted)
+		trimmed = strings.TrimSpace(trimmed[len(quoted):])
+	}
+	return TXTValue{Text: text.String()}, nil
+}
+
+func (v TXTValue) String() string {
+	return v.Text
+}
+
+// quotedPrefix returns the double quoted string at the start of s, including the quotes. If the string isn't terminated
+// the whole of s is returned.
+func quotedPrefix(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return s[:i+1]
+		}
+	}
+	return s
+}
+
+// parseUint16 parses one of the numeric fields of a record value.
+func parseUint16(field, value string) (int, error) {
+	n, err := strconv.ParseUint(value, 10, 16)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", field, value)
+	}
+	return int(n), nil
+}
+
+// MX returns the value of an MX record.
+func (r *Record) MX() (MXValue, error) {
+	if r.Type != RecordTypeMX {
+		return MXValue{}, fmt.Errorf("record is %s not %s", r.Type, RecordTypeMX)
+	}
+	return ParseMXValue(r.GetValue())
+}
+
+// SRV returns the value of an SRV record.
+func (r *Record) SRV() (SRVValue, error) {
+	if r.Type != RecordTypeSRV {
+		return SRVValue{}, fmt.Errorf("record is %s not %s", r.Type, RecordTypeSRV)
+	}
+	return ParseSRVValue(r.GetValue())
+}
+
+// CAA returns the value of a CAA record.
+func (r *Record) CAA() (CAAValue, error) {
+	if r.Type != RecordTypeCAA {
+		return CAAValue{}, fmt.Errorf("record is %s not %s", r.Type, RecordTypeCAA)
+	}
+	return ParseCAAValue(r.Value)
+}
+
+// TXT returns the value of a TXT record.
+func (r *Record) TXT() (TXTValue, error) {
+	if r.Type != RecordTypeTXT {
+		return TXTValue{}, fmt.Errorf("record is %s not %s", r.Type, RecordTypeTXT)
+	}
+	return ParseTXTValue(r.Value)
+}
+
+// NewMXRecord will create an MX record that can be passed to CreateDNSRecord.
+func NewMXRecord(name string, priority int, host string) *Record {
+	return &Record{
+		Name:       name,
+		Type:       RecordTypeMX,
+		Value:      host,
+		MxPriority: strconv.Itoa(priority),
+	}
+}
+
+// NewSRVRecord will create an SRV record that can be passed to CreateDNSRecord, the name should be in the form
+// _service._proto.name.
+func NewSRVRecord(name string, priority, weight, port int, target string) *Record {
+	return &Record{
+		Name:        name,
+		Type:        RecordTypeSRV,
+		Value:       fmt.Sprintf("%d %d %s", weight, port, target),
+		SrvPriority: strconv.Itoa(priority),
+	}
+}
+
+// NewCAARecord will create a CAA record that can be passed to CreateDNSRecord.
+func NewCAARecord(name string, flags int, tag, value string) *Record {
+	return &Record{
+		Name:  name,
+		Type:  RecordTypeCAA,
+		Value: CAAValue{Flags: flags, Tag: tag, Value: value}.String(),
+	}
+}
+
+// NewTXTRecord will create a TXT record that can be passed to CreateDNSRecord.
+func NewTXTRecord(name, text string) *Record {
+	return &Record{
+		Name:  name,
+		Type:  RecordTypeTXT,
+		Value: text,
+	}
+}
@@ -0,0 +1,106 @@
+package zeit
+
+import (
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseMXValue(t *testing.T) {
+	a := assert.New(t)
+
+	mx, err := ParseMXValue("10 aspmx.l.google.com")
+	a.Nil(err, "Error should be nil")
+	a.Equal(MXValue{Priority: 10, Host: "aspmx.l.google.com"}, mx)
+	a.Equal("10 aspmx.l.google.com", mx.String())
+
+	for _, value := range []string{"", "aspmx.l.google.com", "ten aspmx.l.google.com", "70000 aspmx.l.google.com"} {
+		_, err := ParseMXValue(value)
+		a.Error(err, "%q should be invalid", value)
+	}
+}
+
+func TestParseSRVValue(t *testing.T) {
+	a := assert.New(t)
+
+	srv, err := ParseSRVValue("10 20 5060 sip.example.com.")
+	a.Nil(err, "Error should be nil")
+	a.Equal(SRVValue{Priority: 10, Weight: 20, Port: 5060, Target: "sip.example.com."}, srv)
+	a.Equal("10 20 5060 sip.example.com.", srv.String())
+
+	for _, value := range []string{"", "10 20 sip.example.com", "10 20 port sip.example.com", "10 -1 80 sip.example.com"} {
+		_, err := ParseSRVValue(value)
+		a.Error(err, "%q should be invalid", value)
+	}
+}
+
+func TestParseCAAValue(t *testing.T) {
+	a := assert.New(t)
+
+	caa, err := ParseCAAValue(`0 issue "letsencrypt.org"`)
+	a.Nil(err, "Error should be nil")
+	a.Equal(CAAValue{Flags: 0, Tag: "issue", Value: "letsencrypt.org"}, caa)
+	a.Equal(`0 issue "letsencrypt.org"`, caa.String())
+
+	caa, err = ParseCAAValue("128 iodef mailto:security@example.com")
+	a.Nil(err, "unquoted values should be accepted")
+	a.Equal(CAAValue{Flags: 128, Tag: "iodef", Value: "mailto:security@example.com"}, caa)
+
+	for _, value := range []string{"", "0 issue", "256 issue letsencrypt.org"} {
+		_, err := ParseCAAValue(value)
+		a.Error(err, "%q should be invalid", value)
+	}
+}
+
+func TestParseTXTValue(t *testing.T) {
+	a := assert.New(t)
+
+	txt, err := ParseTXTValue("v=spf1 include:_spf.google.com ~all")
+	a.Nil(err, "Error should be nil")
+	a.Equal("v=spf1 include:_spf.google.com ~all", txt.String())
+
+	txt, err = ParseTXTValue(`"hello " "world \"quoted\""`)
+	a.Nil(err, "Error should be nil")
+	a.Equal(`hello world "quoted"`, txt.Text, "quoted strings should be joined")
+
+	_, err = ParseTXTValue(`"unterminated`)
+	a.Error(err, "unterminated strings should be invalid")
+}
+
+func TestRecordConstructors(t *testing.T) {
+	a := assert.New(t)
+
+	srv, err := NewSRVRecord("_sip._tcp", 10, 20, 5060, "sip.example.com").SRV()
+	a.Nil(err, "Error should be nil")
+	a.Equal(SRVValue{Priority: 10, Weight: 20, Port: 5060, Target: "sip.example.com"}, srv)
+
+	mx, err := NewMXRecord("", 5, "mail.example.com").MX()
+	a.Nil(err, "Error should be nil")
+	a.Equal(MXValue{Priority: 5, Host: "mail.example.com"}, mx)
+
+	caa, err := NewCAARecord("", 0, "issue", "letsencrypt.org").CAA()
+	a.Nil(err, "Error should be nil")
+	a.Equal(CAAValue{Tag: "issue", Value: "letsencrypt.org"}, caa)
+
+	txt, err := NewTXTRecord("_dmarc", "v=DMARC1; p=none").TXT()
+	a.Nil(err, "Error should be nil")
+	a.Equal("v=DMARC1; p=none", txt.Text)
+
+	_, err = NewTXTRecord("", "hello").MX()
+	a.Error(err, "records of another type should not be parsed")
+}
+
+func TestClient_CreateDNSRecord_Typed(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	_, err := client.CreateDNSRecord("example.com", NewSRVRecord("_sip._tcp", 10, 20, 5060, "sip.example.com."))
+	a.Nil(err, "Error should be nil")
+
+	records := server.Records("", "example.com")
+	a.Len(records, 1)
+	a.Equal("10 20 5060 sip.example.com", records[0].Value, "value should be sent in the wire format")
+}
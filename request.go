@@ -0,0 +1,82 @@
+package zeit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+)
+
+// maxDrainSize is the most that is read from an unread response body before it is closed, draining the body lets the
+// connection be reused.
+const maxDrainSize = 1 << 16
+
+// request describes a single call to the API made with do. Every endpoint should be called through do so bodies are
+// always encoded, decoded and closed the same way.
+type request struct {
+	// method is the http method of the request.
+	method string
+	// endpoint is the path of the request relative to the root url, without a leading slash.
+	endpoint string
+	// query is added to the url of the request.
+	query url.Values
+	// body is encoded as json and sent as the body of the request if it is defined.
+	body interface{}
+	// raw is sent as the body of the request unchanged if it is defined, instead of body.
+	raw []byte
+	// header is added to the headers of the request, its Content-Type replaces the default of application/json.
+	header http.Header
+	// result is decoded from the json body of a successful response if it is defined.
+	result interface{}
+	// wrapError wraps the APIError of an unsuccessful response in a more specific error if it is defined.
+	wrapError func(*APIError) error
+}
+
+// do will send the request and decode the response into the result of the request. Any response with a status code
+// outside of 2xx is returned as an error, see errorFromResponse. The response body is always drained and closed.
+func (c Client) do(ctx context.Context, r request) error {
+	var body io.Reader
+	if r.body != nil {
+		data, err := json.Marshal(r.body)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	if r.raw != nil {
+		body = bytes.NewReader(r.raw)
+	}
+
+	endpoint := r.endpoint
+	if len(r.query) > 0 {
+		endpoint = endpoint + "?" + r.query.Encode()
+	}
+
+	resp, err := c.makeAndDoRequest(ctx, r.method, endpoint, body, r.header)
+	if err != nil {
+		return err
+	}
+	defer closeResponseBody(resp)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return errorFromResponse(resp, r.wrapError)
+	}
+
+	if r.result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(r.result)
+}
+
+// closeResponseBody is a helper function to drain and close the body of a http response. It is safe to call with a nil
+// response, errors are ignored as the body has already been read.
+func closeResponseBody(resp *http.Response) {
+	if resp == nil || resp.Body == nil {
+		return
+	}
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(resp.Body, maxDrainSize))
+	_ = resp.Body.Close()
+}
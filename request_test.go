@@ -0,0 +1,139 @@
+package zeit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+// trackedBody records whether a response body was closed.
+type trackedBody struct {
+	*bytes.Reader
+	closed bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed = true
+	return errors.New("close errors should be ignored")
+}
+
+func TestClient_Do(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	body := &trackedBody{Reader: bytes.NewReader([]byte(`{"uid":"rec_123"}`))}
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		a.Equal(http.MethodPost, req.Method)
+		a.Equal("/v2/test", req.URL.Path)
+		a.Equal("b", req.URL.Query().Get("a"))
+		a.Equal("application/json", req.Header.Get("Content-Type"))
+
+		data, err := ioutil.ReadAll(req.Body)
+		a.Nil(err)
+		a.JSONEq(`{"name":"foo"}`, string(data), "body should be encoded as json")
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: body}, nil
+	})
+
+	var uid string
+	err := client.do(context.Background(), request{
+		method:   http.MethodPost,
+		endpoint: "v2/test",
+		query:    map[string][]string{"a": {"b"}},
+		body: struct {
+			Name string `json:"name"`
+		}{"foo"},
+		result: &struct {
+			Uid *string `json:"uid"`
+		}{&uid},
+	})
+	a.Nil(err, "Error should be nil")
+	a.Equal("rec_123", uid, "result should be decoded")
+	a.True(body.closed, "body should be closed")
+}
+
+func TestClient_DoError(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+	}
+
+	response, err := json.Marshal(map[string]interface{}{
+		"error": map[string]string{"code": "forbidden", "message": "Not allowed"},
+	})
+	a.Nil(err)
+	body := &trackedBody{Reader: bytes.NewReader(response)}
+	mockHttpClient.EXPECT().Do(gomock.Any()).Return(
+		&http.Response{StatusCode: http.StatusForbidden, Header: http.Header{}, Body: body}, nil,
+	)
+
+	err = client.do(context.Background(), request{method: http.MethodGet, endpoint: "v2/test"})
+	a.True(errors.Is(err, ErrForbidden), "error status should be returned as an APIError")
+	a.Equal("GET v2/test: 403 forbidden: Not allowed", err.Error())
+	a.True(body.closed, "body should be closed")
+}
+
+func TestClient_DoHttpClientError(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := Client{
+		token:       TestToken,
+		rootUrl:     rootUrl,
+		httpClient:  mockHttpClient,
+		rateLimit:   &rateLimit{},
+		retryPolicy: RetryPolicy{MaxAttempts: 1},
+	}
+
+	mockHttpClient.EXPECT().Do(gomock.Any()).Return(nil, errors.New("connection refused"))
+
+	a.NotPanics(func() {
+		err := client.RemoveDNSRecord("test.com", "rec_123")
+		a.EqualError(err, "connection refused")
+	}, "a failed request should not panic")
+}
+
+func TestClient_CheckDomainAvailability_Query(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := NewClient(TestToken, WithBaseURL(rootUrl), WithHTTPClient(mockHttpClient), WithTeam("team_123"))
+
+	httpResponse := makeResponse([]byte(`{"available":true}`), http.StatusOK)
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		a.Equal("/v4/domains/status", req.URL.Path)
+		a.Equal("test.com", req.URL.Query().Get("name"), "domain should be sent as the name parameter")
+		a.Equal("team_123", req.URL.Query().Get("teamId"), "team should be added to the query")
+		return &httpResponse, nil
+	})
+
+	available, err := client.CheckDomainAvailability("test.com")
+	a.Nil(err, "Error should be nil")
+	a.True(available)
+}
@@ -0,0 +1,122 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy controls how requests that fail with a server error, a connection reset or a timeout are retried. Only
+// idempotent requests (GET, HEAD, OPTIONS, PUT and DELETE) are retried unless RetryNonIdempotent is set or the request
+// context was created with ContextWithIdempotent. Requests rejected with 429 Too Many Requests were never processed so
+// they are always sent again, up to MaxAttempts.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times a request is sent, including the first attempt. A value of one disables
+	// retries and zero uses DefaultRetryPolicy.
+	MaxAttempts int
+	// MinBackoff is the time waited before the first retry, each retry after that waits twice as long.
+	MinBackoff time.Duration
+	// MaxBackoff caps the time waited between retries.
+	MaxBackoff time.Duration
+	// RetryNonIdempotent will also retry POST and PATCH requests, such as CreateDNSRecord and BuyDomain. Only set this
+	// if sending the same request twice is safe for you.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is set, three attempts waiting between half a second and
+// thirty seconds.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		MinBackoff:  500 * time.Millisecond,
+		MaxBackoff:  30 * time.Second,
+	}
+}
+
+// backoff returns the time to wait after the given attempt, the exponential backoff is jittered so clients that failed
+// together don't all retry at the same moment.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.MinBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// retry returns the retry policy used by the client.
+func (c Client) retry() RetryPolicy {
+	if c.retryPolicy.MaxAttempts == 0 {
+		return DefaultRetryPolicy()
+	}
+	return c.retryPolicy
+}
+
+type idempotentContextKey struct{}
+
+// ContextWithIdempotent returns a context that marks any request made with it as safe to retry, even if the request
+// isn't idempotent. This can be used to opt in to retries for a single call to a method such as CreateDNSRecord.
+func ContextWithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentContextKey{}, true)
+}
+
+func idempotentFromContext(ctx context.Context) bool {
+	idempotent, _ := ctx.Value(idempotentContextKey{}).(bool)
+	return idempotent
+}
+
+// isIdempotent reports whether sending a request with the http method more than once has the same effect as sending it
+// once.
+func isIdempotent(httpMethod string) bool {
+	switch httpMethod {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus reports whether a response with the status code is worth retrying.
+func isRetryableStatus(statusCode int) bool {
+	return statusCode >= http.StatusInternalServerError && statusCode != http.StatusNotImplemented
+}
+
+// isTemporaryError reports whether the error returned by the HttpClient is a connection reset or a timeout.
+func isTemporaryError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter parses the Retry-After header, which is either a number of seconds or a http date.
+func retryAfter(header http.Header) (time.Duration, bool) {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		d := time.Until(date)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
@@ -0,0 +1,148 @@
+package zeit
+
+import (
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func retryTestClient(mockHttpClient *mocks.MockHttpClient) Client {
+	return Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mockHttpClient,
+		rateLimit:  &rateLimit{},
+		retryPolicy: RetryPolicy{
+			MaxAttempts: 3,
+			MinBackoff:  time.Millisecond,
+			MaxBackoff:  5 * time.Millisecond,
+		},
+	}
+}
+
+func TestClient_RetryServerError(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := retryTestClient(mockHttpClient)
+
+	unavailable := makeResponse([]byte(`{}`), http.StatusServiceUnavailable)
+	ok := makeResponse([]byte(`{"domains":[]}`), http.StatusOK)
+	gomock.InOrder(
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(nil, syscall.ECONNRESET),
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&unavailable, nil),
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&ok, nil),
+	)
+
+	_, err := client.ListAllDomains()
+	a.Nil(err, "should succeed after retrying")
+}
+
+func TestClient_RetryGivesUp(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := retryTestClient(mockHttpClient)
+
+	mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		resp := makeResponse([]byte(`{}`), http.StatusBadGateway)
+		return &resp, nil
+	}).Times(3)
+
+	err := client.RemoveDNSRecord("test.com", "123456")
+	a.Error(err, "should fail once every attempt has been used")
+}
+
+func TestClient_RetryNonIdempotent(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := retryTestClient(mockHttpClient)
+
+	t.Run("not retried by default", func(t *testing.T) {
+		unavailable := makeResponse([]byte(`{}`), http.StatusServiceUnavailable)
+		mockHttpClient.EXPECT().Do(gomock.Any()).Return(&unavailable, nil)
+
+		err := client.BuyDomain("test.com", 10)
+		a.Error(err, "POST requests should not be retried")
+	})
+
+	t.Run("retried with context", func(t *testing.T) {
+		var bodies []string
+		mockHttpClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+			body := make([]byte, req.ContentLength)
+			_, _ = req.Body.Read(body)
+			bodies = append(bodies, string(body))
+			if len(bodies) == 1 {
+				resp := makeResponse([]byte(`{}`), http.StatusServiceUnavailable)
+				return &resp, nil
+			}
+			resp := makeResponse([]byte(`{}`), http.StatusOK)
+			return &resp, nil
+		}).Times(2)
+
+		err := client.BuyDomainContext(ContextWithIdempotent(context.Background()), "test.com", 10)
+		a.Nil(err, "Error should be nil")
+		a.Equal(bodies[0], bodies[1], "the body should be sent again")
+	})
+}
+
+func TestClient_RetryContextCancelled(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockHttpClient := mocks.NewMockHttpClient(ctrl)
+	client := retryTestClient(mockHttpClient)
+	client.retryPolicy.MinBackoff = time.Hour
+	client.retryPolicy.MaxBackoff = time.Hour
+
+	unavailable := makeResponse([]byte(`{}`), http.StatusServiceUnavailable)
+	mockHttpClient.EXPECT().Do(gomock.Any()).Return(&unavailable, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.ListAllDomainsContext(ctx)
+	a.Equal(context.DeadlineExceeded, err, "should stop retrying when the context is done")
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	a := assert.New(t)
+
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 1; attempt < 10; attempt++ {
+		d := policy.backoff(attempt)
+		a.True(d >= 50*time.Millisecond, "backoff should be at least half the minimum")
+		a.True(d <= time.Second, "backoff should not exceed the maximum")
+	}
+	a.True(policy.backoff(3) >= 200*time.Millisecond, "backoff should grow with each attempt")
+}
+
+func TestRetryAfter(t *testing.T) {
+	a := assert.New(t)
+
+	header := http.Header{}
+	_, ok := retryAfter(header)
+	a.False(ok, "missing header should not be parsed")
+
+	header.Set("Retry-After", "3")
+	d, ok := retryAfter(header)
+	a.True(ok)
+	a.Equal(3*time.Second, d)
+
+	header.Set("Retry-After", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	d, ok = retryAfter(header)
+	a.True(ok)
+	a.True(d > 58*time.Second && d <= time.Minute, "http dates should be parsed")
+}
@@ -0,0 +1,232 @@
+package zeit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by WriteSnapshot.
+const SnapshotVersion = 1
+
+// Snapshot is the state of a domain and its DNS records at a point in time.
+type Snapshot struct {
+	Version int       `json:"version"`
+	Taken   time.Time `json:"taken"`
+	Domain  Domain    `json:"domain"`
+	Records []Record  `json:"records"`
+}
+
+// RestoreOptions changes how RestoreSnapshot restores a domain.
+type RestoreOptions struct {
+	// DeleteExtra removes records that aren't in the snapshot, by default they are kept.
+	DeleteExtra bool
+}
+
+// RestoreResult is what RestoreSnapshot changed.
+type RestoreResult struct {
+	// Ids maps the id of every record in the snapshot to the id of the record that now has its value. Records
+	// recreated by the restore are given new ids.
+	Ids map[string]string
+	// Created is the ids of the records recreated by the restore.
+	Created []string
+	// Deleted is the ids of the records removed because they weren't in the snapshot.
+	Deleted []string
+}
+
+// SnapshotDomain will capture the domain and every one of its DNS records so they can be restored later with
+// RestoreSnapshot.
+func (c Client) SnapshotDomain(domain string) (*Snapshot, error) {
+	return c.SnapshotDomainContext(context.Background(), domain)
+}
+
+// SnapshotDomainContext is the same as SnapshotDomain but the requests are bound to the given context.
+func (c Client) SnapshotDomainContext(ctx context.Context, domain string) (*Snapshot, error) {
+	d, err := c.GetDomainContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	records, err := c.ListDNSRecordsContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Version: SnapshotVersion, Taken: time.Now().UTC(), Domain: *d, Records: records}, nil
+}
+
+// SnapshotAll will capture every domain of the account, or of the team the client is scoped to.
+func (c Client) SnapshotAll() ([]*Snapshot, error) {
+	return c.SnapshotAllContext(context.Background())
+}
+
+// SnapshotAllContext is the same as SnapshotAll but the requests are bound to the given context.
+func (c Client) SnapshotAllContext(ctx context.Context) ([]*Snapshot, error) {
+	domains, err := c.ListAllDomainsContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]*Snapshot, 0, len(domains))
+	for _, domain := range domains {
+		records, err := c.ListDNSRecordsContext(ctx, domain.Name)
+		if err != nil {
+			return nil, fmt.Errorf("snapshot of %s: %w", domain.Name, err)
+		}
+		snapshots = append(snapshots, &Snapshot{
+			Version: SnapshotVersion,
+			Taken:   time.Now().UTC(),
+			Domain:  domain,
+			Records: records,
+		})
+	}
+	return snapshots, nil
+}
+
+// WriteSnapshot will write the snapshot to w as JSON.
+func WriteSnapshot(w io.Writer, snapshot *Snapshot) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(snapshot)
+}
+
+// ReadSnapshot will read a snapshot written by WriteSnapshot. Snapshots written by a newer version of the package
+// are rejected.
+func ReadSnapshot(r io.Reader) (*Snapshot, error) {
+	snapshot := &Snapshot{}
+	if err := json.NewDecoder(r).Decode(snapshot); err != nil {
+		return nil, err
+	}
+	if snapshot.Version < 1 || snapshot.Version > SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", snapshot.Version)
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshot will recreate every record of the snapshot that is missing from the domain. A record is present if
+// a record with its name, type and value exists, recreated records are given new ids which are mapped in the result.
+// The domain itself must still exist, only its records are restored. A CNAME can't share its name with other records,
+// so restoring one over records added since only succeeds with DeleteExtra, which removes them first.
+func (c Client) RestoreSnapshot(snapshot *Snapshot, opts RestoreOptions) (*RestoreResult, error) {
+	return c.RestoreSnapshotContext(context.Background(), snapshot, opts)
+}
+
+// RestoreSnapshotContext is the same as RestoreSnapshot but the requests are bound to the given context. If a request
+// fails the result of the changes made so far is returned along with the error.
+func (c Client) RestoreSnapshotContext(ctx context.Context, snapshot *Snapshot, opts RestoreOptions) (*RestoreResult, error) {
+	domain := snapshot.Domain.Name
+	current, err := c.ListDNSRecordsContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]string)
+	byValue := make(map[string][]string)
+	for _, record := range current {
+		key := snapshotKey(&record)
+		byId[record.Id] = key
+		byValue[key] = append(byValue[key], record.Id)
+	}
+
+	result := &RestoreResult{Ids: make(map[string]string)}
+	kept := make(map[string]bool)
+	var missing []Record
+	for _, record := range snapshot.Records {
+		if key, ok := byId[record.Id]; ok && key == snapshotKey(&record) && !kept[record.Id] {
+			result.Ids[record.Id] = record.Id
+			kept[record.Id] = true
+			continue
+		}
+		if id, ok := takeUnkept(byValue[snapshotKey(&record)], kept); ok {
+			result.Ids[record.Id] = id
+			kept[id] = true
+			continue
+		}
+		missing = append(missing, record)
+	}
+
+	// a CNAME can't share its name with other records, so the records that would conflict with a restored record are
+	// removed before it is created
+	removed := make(map[string]bool)
+	if opts.DeleteExtra {
+		conflicting := cnameConflicts(missing, current, kept)
+		for _, record := range current {
+			if kept[record.Id] || !conflicting[record.Name] {
+				continue
+			}
+			if err := c.RemoveDNSRecordContext(ctx, domain, record.Id); err != nil {
+				return result, err
+			}
+			removed[record.Id] = true
+			result.Deleted = append(result.Deleted, record.Id)
+		}
+	}
+
+	for _, record := range missing {
+		uid, err := c.CreateDNSRecordContext(ctx, domain, &Record{
+			Name:        record.Name,
+			Type:        record.Type,
+			Value:       record.Value,
+			TTL:         record.TTL,
+			MxPriority:  record.MxPriority,
+			SrvPriority: record.SrvPriority,
+		})
+		if err != nil {
+			return result, fmt.Errorf("restoring %s %s: %w", record.Name, record.Type, err)
+		}
+		result.Ids[record.Id] = uid
+		result.Created = append(result.Created, uid)
+	}
+
+	if opts.DeleteExtra {
+		for _, record := range current {
+			if kept[record.Id] || removed[record.Id] {
+				continue
+			}
+			if err := c.RemoveDNSRecordContext(ctx, domain, record.Id); err != nil {
+				return result, err
+			}
+			result.Deleted = append(result.Deleted, record.Id)
+		}
+	}
+	return result, nil
+}
+
+// cnameConflicts returns the names of the missing records where a CNAME would share its name with another record,
+// either because a CNAME is missing or because a record that isn't kept is a CNAME.
+func cnameConflicts(missing, current []Record, kept map[string]bool) map[string]bool {
+	cnames := make(map[string]bool)
+	for _, record := range missing {
+		if record.Type == RecordTypeCNAME {
+			cnames[record.Name] = true
+		}
+	}
+	for _, record := range current {
+		if !kept[record.Id] && record.Type == RecordTypeCNAME {
+			cnames[record.Name] = true
+		}
+	}
+	conflicting := make(map[string]bool)
+	for _, record := range missing {
+		if cnames[record.Name] {
+			conflicting[record.Name] = true
+		}
+	}
+	return conflicting
+}
+
+// snapshotKey identifies a record by its name, type and value.
+func snapshotKey(r *Record) string {
+	value := strings.ToLower(strings.TrimSuffix(strings.TrimSpace(r.GetValue()), "."))
+	return r.Name + " " + r.Type + " " + value
+}
+
+// takeUnkept returns the first id that hasn't already been matched to a record of the snapshot.
+func takeUnkept(ids []string, kept map[string]bool) (string, bool) {
+	for _, id := range ids {
+		if !kept[id] {
+			return id, true
+		}
+	}
+	return "", false
+}
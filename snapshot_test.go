@@ -0,0 +1,123 @@
+package zeit
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_SnapshotDomain(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	kept, err := server.AddRecord("", "example.com", zeittest.Record{Name: "www", Type: RecordTypeA, Value: "1.1.1.1"})
+	a.Nil(err)
+	lost, err := server.AddRecord("", "example.com", zeittest.Record{Name: "mail", Type: RecordTypeMX, Value: "10 mail.example.com"})
+	a.Nil(err)
+	changed, err := server.AddRecord("", "example.com", zeittest.Record{Name: "api", Type: RecordTypeTXT, Value: "a"})
+	a.Nil(err)
+
+	snapshot, err := client.SnapshotDomain("example.com")
+	a.Nil(err, "Error should be nil")
+	a.Equal(SnapshotVersion, snapshot.Version)
+	a.Equal("example.com", snapshot.Domain.Name)
+	a.Len(snapshot.Records, 3)
+
+	// the snapshot should survive being written to a file
+	created := snapshot.Records[0].Created.Time
+	buf := &bytes.Buffer{}
+	a.Nil(WriteSnapshot(buf, snapshot))
+	snapshot, err = ReadSnapshot(buf)
+	a.Nil(err, "Error should be nil")
+	a.Len(snapshot.Records, 3)
+	a.True(created.Equal(snapshot.Records[0].Created.Time), "times should round trip")
+
+	// break the domain
+	a.Nil(client.RemoveDNSRecord("example.com", lost))
+	a.Nil(client.UpdateDNSRecord("example.com", changed, &Record{Name: "api", Type: RecordTypeTXT, Value: "b"}))
+	extra, err := client.CreateDNSRecord("example.com", &Record{Name: "extra", Type: RecordTypeA, Value: "2.2.2.2"})
+	a.Nil(err)
+
+	result, err := client.RestoreSnapshot(snapshot, RestoreOptions{})
+	a.Nil(err, "Error should be nil")
+	a.Len(result.Created, 2, "missing and changed records should be recreated")
+	a.Empty(result.Deleted, "extra records should be kept")
+	a.Equal(kept, result.Ids[kept], "untouched records should keep their id")
+	a.NotEqual(lost, result.Ids[lost], "recreated records should have a new id")
+	a.Len(server.Records("", "example.com"), 5)
+
+	result, err = client.RestoreSnapshot(snapshot, RestoreOptions{DeleteExtra: true})
+	a.Nil(err, "Error should be nil")
+	a.Empty(result.Created, "restore should be idempotent")
+	a.ElementsMatch([]string{changed, extra}, result.Deleted)
+
+	records := server.Records("", "example.com")
+	a.Len(records, 3)
+	for _, record := range records {
+		a.NotEqual("b", record.Value)
+	}
+}
+
+func TestClient_RestoreSnapshotCNAME(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	changed, err := server.AddRecord("", "example.com", zeittest.Record{Name: "api", Type: RecordTypeCNAME, Value: "a.example.net"})
+	a.Nil(err)
+	snapshot, err := client.SnapshotDomain("example.com")
+	a.Nil(err, "Error should be nil")
+	a.Nil(client.UpdateDNSRecord("example.com", changed, &Record{Name: "api", Type: RecordTypeCNAME, Value: "b.example.net"}))
+
+	_, err = client.RestoreSnapshot(snapshot, RestoreOptions{})
+	a.Error(err, "a CNAME can't be restored next to the record that replaced it")
+
+	result, err := client.RestoreSnapshot(snapshot, RestoreOptions{DeleteExtra: true})
+	a.Nil(err, "Error should be nil")
+	a.Equal([]string{changed}, result.Deleted)
+	records := server.Records("", "example.com")
+	if a.Len(records, 1) {
+		a.Equal("a.example.net", records[0].Value)
+	}
+}
+
+func TestClient_SnapshotAll(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	server.AddDomain("", "example.net")
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	_, err := server.AddRecord("", "example.net", zeittest.Record{Name: "www", Type: RecordTypeA, Value: "1.1.1.1"})
+	a.Nil(err)
+
+	snapshots, err := client.SnapshotAll()
+	a.Nil(err, "Error should be nil")
+	a.Len(snapshots, 2)
+	for _, snapshot := range snapshots {
+		if snapshot.Domain.Name == "example.net" {
+			a.Len(snapshot.Records, 1)
+		} else {
+			a.Empty(snapshot.Records)
+		}
+	}
+}
+
+func TestReadSnapshot(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := ReadSnapshot(strings.NewReader(`{"version": 2, "domain": {"name": "example.com"}}`))
+	a.EqualError(err, "unsupported snapshot version 2")
+
+	_, err = ReadSnapshot(strings.NewReader(`{"domain": {"name": "example.com"}}`))
+	a.Error(err, "snapshots without a version should be rejected")
+}
@@ -24,3 +24,8 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 	t.Time = time.Unix(0, unixTime*1e6)
 	return nil
 }
+
+// MarshalJSON writes the time as a unix timestamp with millisecond accuracy, the same format it is read from.
+func (t Time) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(t.UnixNano()/1e6, 10)), nil
+}
@@ -1,6 +1,7 @@
 package zeit
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/stretchr/testify/assert"
 	"math"
@@ -46,3 +47,17 @@ func TestTime_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestTime_MarshalJSON(t *testing.T) {
+	a := assert.New(t)
+	original := Time{}
+	a.Nil(original.UnmarshalJSON([]byte("1565000000123")))
+
+	data, err := json.Marshal(original)
+	a.Nil(err)
+	a.Equal("1565000000123", string(data), "should be written as a unix timestamp in milliseconds")
+
+	parsed := Time{}
+	a.Nil(json.Unmarshal(data, &parsed))
+	a.True(original.Equal(parsed.Time), "should round trip")
+}
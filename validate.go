@@ -0,0 +1,198 @@
+package zeit
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// maxTXTStringLength is the longest a single quoted string of a TXT record can be, longer text is split into several
+// strings when it is written to a zone file.
+const maxTXTStringLength = 255
+
+// caaTags are the CAA property tags defined in RFC 8659 and RFC 8657.
+var caaTags = map[string]bool{
+	"issue":        true,
+	"issuewild":    true,
+	"iodef":        true,
+	"contactemail": true,
+	"contactphone": true,
+}
+
+var (
+	hostnameLabel = regexp.MustCompile(`^[a-zA-Z0-9_]([a-zA-Z0-9_-]{0,61}[a-zA-Z0-9_])?$`)
+	srvName       = regexp.MustCompile(`^_[a-zA-Z0-9-]+\._[a-zA-Z]+(\.|$)`)
+)
+
+// RecordError is a single problem found while validating a record.
+type RecordError struct {
+	Name    string
+	Type    string
+	Message string
+}
+
+func (e RecordError) Error() string {
+	name := e.Name
+	if name == "" {
+		name = "@"
+	}
+	return fmt.Sprintf("%s %s: %s", name, e.Type, e.Message)
+}
+
+// ValidationError lists every problem found while validating one or more records.
+type ValidationError struct {
+	Errors []RecordError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	if len(messages) == 1 {
+		return "invalid record: " + messages[0]
+	}
+	return fmt.Sprintf("%d problems found validating records: %s", len(messages), strings.Join(messages, "; "))
+}
+
+// validator collects the problems found with records.
+type validator struct {
+	errors []RecordError
+}
+
+func (v *validator) add(r *Record, format string, args ...interface{}) {
+	v.errors = append(v.errors, RecordError{Name: r.Name, Type: r.Type, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *validator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return &ValidationError{Errors: v.errors}
+}
+
+// Validate checks the record before it is sent to the API, every problem found is returned in a *ValidationError.
+// CreateDNSRecord validates records automatically. Only the record itself is checked, use ValidateRecords to also
+// check it doesn't conflict with other records, such as a CNAME sharing its name with them.
+func (r *Record) Validate() error {
+	v := &validator{}
+	v.record(r)
+	return v.err()
+}
+
+// ValidateRecords validates every record and also checks the records don't conflict with each other, such as a CNAME
+// sharing its name with another record.
+func ValidateRecords(records []Record) error {
+	v := &validator{}
+	byName := make(map[string][]*Record)
+	var names []string
+	for i := range records {
+		record := &records[i]
+		v.record(record)
+		if _, ok := byName[record.Name]; !ok {
+			names = append(names, record.Name)
+		}
+		byName[record.Name] = append(byName[record.Name], record)
+	}
+	for _, name := range names {
+		group := byName[name]
+		for _, record := range group {
+			if record.Type == RecordTypeCNAME && len(group) > 1 {
+				v.add(record, "a CNAME can't share its name with any other record, found %d records", len(group))
+				break
+			}
+		}
+	}
+	return v.err()
+}
+
+// record checks a single record.
+func (v *validator) record(r *Record) {
+	if r.Name == "@" {
+		v.add(r, ErrorOrigin)
+	} else if r.Name != "" && r.Name != "*" && !isHostname(strings.TrimPrefix(r.Name, "*.")) {
+		v.add(r, "name %q is not a valid hostname", r.Name)
+	}
+	if r.Value == "" {
+		v.add(r, "value is empty")
+		return
+	}
+
+	switch r.Type {
+	case RecordTypeA:
+		if ip := net.ParseIP(r.Value); ip == nil || ip.To4() == nil || strings.Contains(r.Value, ":") {
+			v.add(r, "value %q is not an IPv4 address", r.Value)
+		}
+	case RecordTypeAAAA:
+		if ip := net.ParseIP(r.Value); ip == nil || !strings.Contains(r.Value, ":") {
+			v.add(r, "value %q is not an IPv6 address", r.Value)
+		}
+	case RecordTypeALIAS, RecordTypeCNAME:
+		if !isHostname(r.Value) {
+			v.add(r, "value %q is not a valid hostname", r.Value)
+		}
+	case RecordTypeMX:
+		mx, err := r.MX()
+		if err != nil {
+			v.add(r, err.Error())
+		} else if !isHostname(mx.Host) {
+			v.add(r, "mail server %q is not a valid hostname", mx.Host)
+		}
+	case RecordTypeSRV:
+		if !srvName.MatchString(r.Name) {
+			v.add(r, "name %q should start with _service._proto", r.Name)
+		}
+		srv, err := r.SRV()
+		if err != nil {
+			v.add(r, err.Error())
+		} else if srv.Target != "." && !isHostname(srv.Target) {
+			v.add(r, "target %q is not a valid hostname", srv.Target)
+		}
+	case RecordTypeCAA:
+		caa, err := r.CAA()
+		if err != nil {
+			v.add(r, err.Error())
+		} else if !caaTags[strings.ToLower(caa.Tag)] {
+			v.add(r, "unknown CAA tag %q", caa.Tag)
+		}
+	case RecordTypeTXT:
+		v.txt(r)
+	default:
+		v.add(r, "unknown record type %q", r.Type)
+	}
+}
+
+// txt checks each quoted string of a TXT record is short enough. Text that isn't quoted is stored by the API as is,
+// whatever its length, so long values such as DKIM keys can be sent without splitting them.
+func (v *validator) txt(r *Record) {
+	value := strings.TrimSpace(r.Value)
+	if !strings.HasPrefix(value, `"`) {
+		return
+	}
+	if _, err := ParseTXTValue(value); err != nil {
+		v.add(r, err.Error())
+		return
+	}
+	for value != "" {
+		quoted := quotedPrefix(value)
+		if len(quoted)-2 > maxTXTStringLength {
+			v.add(r, "string %s is longer than %d characters", quoted, maxTXTStringLength)
+		}
+		value = strings.TrimSpace(value[len(quoted):])
+	}
+}
+
+// isHostname reports whether the name is a valid hostname, a single trailing dot is allowed.
+func isHostname(name string) bool {
+	name = strings.TrimSuffix(name, ".")
+	if name == "" || len(name) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+package zeit
+
+import (
+	"errors"
+	"github.com/golang/mock/gomock"
+	"github.com/kochie/zeit-api-go/mocks"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestRecord_Validate(t *testing.T) {
+	valid := []*Record{
+		{Name: "", Type: RecordTypeA, Value: "1.1.1.1"},
+		{Name: "www", Type: RecordTypeAAAA, Value: "2606:4700::1111"},
+		{Name: "*.dev", Type: RecordTypeCNAME, Value: "alias.zeit.co."},
+		{Name: "", Type: RecordTypeALIAS, Value: "google.com."},
+		NewMXRecord("", 10, "aspmx.l.google.com"),
+		NewSRVRecord("_sip._tcp", 10, 20, 5060, "sip.example.com."),
+		NewCAARecord("", 0, "issue", "letsencrypt.org"),
+		NewTXTRecord("_dmarc", "v=DMARC1; p=none"),
+		{Name: "", Type: RecordTypeTXT, Value: `"` + strings.Repeat("a", 255) + `" "b"`},
+		NewTXTRecord("dkim._domainkey", "v=DKIM1; k=rsa; p="+strings.Repeat("a", 400)),
+	}
+	for _, record := range valid {
+		t.Run(record.Type+"_valid", func(t *testing.T) {
+			assert.Nil(t, record.Validate(), "record should be valid")
+		})
+	}
+
+	invalid := []*Record{
+		{Name: "foo", Type: RecordTypeA, Value: "2606:4700::1111"},
+		{Name: "foo", Type: RecordTypeA, Value: "1.1.1"},
+		{Name: "foo", Type: RecordTypeAAAA, Value: "1.1.1.1"},
+		{Name: "foo", Type: RecordTypeCNAME, Value: "not a host"},
+		{Name: "foo", Type: RecordTypeALIAS, Value: "-bad.example.com"},
+		{Name: "", Type: RecordTypeMX, Value: "mail.example.com"},
+		{Name: "", Type: RecordTypeMX, Value: "bad_host!", MxPriority: "10"},
+		{Name: "sip", Type: RecordTypeSRV, Value: "20 5060 sip.example.com", SrvPriority: "10"},
+		{Name: "", Type: RecordTypeCAA, Value: `0 issuer "letsencrypt.org"`},
+		{Name: "", Type: RecordTypeTXT, Value: `"` + strings.Repeat("a", 256) + `"`},
+		{Name: "foo", Type: "SPF", Value: "v=spf1 -all"},
+		{Name: "bad name", Type: RecordTypeA, Value: "1.1.1.1"},
+		{Name: "foo", Type: RecordTypeA, Value: ""},
+		{Name: "@", Type: RecordTypeA, Value: "1.1.1.1"},
+	}
+	for _, record := range invalid {
+		t.Run(record.Type+"_invalid", func(t *testing.T) {
+			err := record.Validate()
+			var validationError *ValidationError
+			assert.True(t, errors.As(err, &validationError), "record should be invalid")
+		})
+	}
+}
+
+func TestRecord_ValidateEveryProblem(t *testing.T) {
+	a := assert.New(t)
+
+	record := &Record{Name: "sip", Type: RecordTypeSRV, Value: "bad", SrvPriority: "10"}
+	err := record.Validate()
+
+	var validationError *ValidationError
+	a.True(errors.As(err, &validationError))
+	a.Len(validationError.Errors, 2, "every problem should be listed")
+	a.Contains(err.Error(), "2 problems")
+}
+
+func TestValidateRecords(t *testing.T) {
+	a := assert.New(t)
+
+	a.Nil(ValidateRecords([]Record{
+		{Name: "www", Type: RecordTypeCNAME, Value: "example.com"},
+		{Name: "", Type: RecordTypeA, Value: "1.1.1.1"},
+		{Name: "", Type: RecordTypeA, Value: "1.1.1.2"},
+	}), "records should be valid")
+
+	err := ValidateRecords([]Record{
+		{Name: "www", Type: RecordTypeCNAME, Value: "example.com"},
+		{Name: "www", Type: RecordTypeTXT, Value: "hello"},
+		{Name: "", Type: RecordTypeA, Value: "1.1.1"},
+	})
+	var validationError *ValidationError
+	a.True(errors.As(err, &validationError))
+	a.Len(validationError.Errors, 2, "conflicting CNAME and invalid record should be reported")
+}
+
+func TestClient_CreateDNSRecord_Invalid(t *testing.T) {
+	a := assert.New(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := Client{
+		token:      TestToken,
+		rootUrl:    rootUrl,
+		httpClient: mocks.NewMockHttpClient(ctrl),
+		rateLimit:  &rateLimit{},
+	}
+
+	uid, err := client.CreateDNSRecord("test.com", &Record{Name: "www", Type: RecordTypeA, Value: "::1"})
+	var validationError *ValidationError
+	a.True(errors.As(err, &validationError), "invalid records should not be sent")
+	a.Empty(uid, "uid should be empty")
+}
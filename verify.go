@@ -0,0 +1,135 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Defaults used by EnsureVerified when VerifyOptions leaves them unset.
+const (
+	DefaultVerifyTimeout     = 10 * time.Minute
+	DefaultVerifyMinInterval = 5 * time.Second
+	DefaultVerifyMaxInterval = time.Minute
+)
+
+// verificationTXTName is the label of the TXT record checked by ZEIT to verify a domain, the record of example.com is
+// _now.example.com.
+const verificationTXTName = "_now"
+
+// DNSProvider creates records with the DNS provider serving a domain, when its nameservers aren't ZEIT's.
+type DNSProvider interface {
+	// CreateTXTRecord creates a TXT record with the fully qualified name and value.
+	CreateTXTRecord(ctx context.Context, name, value string) error
+}
+
+// VerifyStepKind is what happened in a step of EnsureVerified.
+type VerifyStepKind int
+
+const (
+	// VerifyAttempt is sent before VerifyDomain is called.
+	VerifyAttempt VerifyStepKind = iota
+	// VerifyRecordCreated is sent after the TXT record has been created with the DNSProvider.
+	VerifyRecordCreated
+	// VerifyPending is sent when the domain isn't verified yet, before waiting to try again.
+	VerifyPending
+	// VerifyVerified is sent once the domain is verified.
+	VerifyVerified
+)
+
+// VerifyStep reports the progress of EnsureVerified.
+type VerifyStep struct {
+	Kind    VerifyStepKind
+	Attempt int
+	// Err is why the domain isn't verified yet, set for VerifyPending.
+	Err *VerificationError
+	// Wait is how long until the next attempt, set for VerifyPending.
+	Wait time.Duration
+	// RecordName and RecordValue are the fully qualified name and value of the TXT record, set for
+	// VerifyRecordCreated.
+	RecordName  string
+	RecordValue string
+}
+
+// VerifyOptions changes how EnsureVerified verifies a domain.
+type VerifyOptions struct {
+	// Provider creates the TXT record that verifies the domain. If nil no record is created and the domain is verified
+	// once its nameservers or TXT record are set by hand.
+	Provider DNSProvider
+	// Timeout is how long to keep trying, zero uses DefaultVerifyTimeout.
+	Timeout time.Duration
+	// MinInterval and MaxInterval bound the backoff between attempts, zero uses DefaultVerifyMinInterval and
+	// DefaultVerifyMaxInterval.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// OnStep is called with each step taken.
+	OnStep func(VerifyStep)
+}
+
+// EnsureVerified will verify the domain, waiting until it succeeds or the timeout is reached. When the domain can't be
+// verified the TXT record from the VerificationError is created with the Provider, if there is one, and VerifyDomain
+// is tried again with exponential backoff. If the domain isn't verified in time the error wraps the last
+// *VerificationError.
+func (c Client) EnsureVerified(ctx context.Context, domain string, opts VerifyOptions) (*Domain, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultVerifyTimeout
+	}
+	policy := RetryPolicy{MinBackoff: opts.MinInterval, MaxBackoff: opts.MaxInterval}
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = DefaultVerifyMinInterval
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultVerifyMaxInterval
+	}
+	step := func(s VerifyStep) {
+		if opts.OnStep != nil {
+			opts.OnStep(s)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	created := false
+	var last *VerificationError
+	for attempt := 1; ; attempt++ {
+		step(VerifyStep{Kind: VerifyAttempt, Attempt: attempt})
+		d, err := c.VerifyDomainContext(ctx, domain)
+		if err == nil {
+			step(VerifyStep{Kind: VerifyVerified, Attempt: attempt})
+			return d, nil
+		}
+		if ctx.Err() != nil && last != nil {
+			return nil, fmt.Errorf("domain %s not verified after %d attempts: %w", domain, attempt-1, last)
+		}
+		// every error verifying a domain is a VerificationError, only those saying what is required can be waited on
+		var verificationError *VerificationError
+		if !errors.As(err, &verificationError) || !verificationError.pending() {
+			return nil, err
+		}
+		last = verificationError
+
+		if opts.Provider != nil && !created && verificationError.TxtVerification.VerificationRecord != "" {
+			name := verificationTXTName + "." + domain
+			value := verificationError.TxtVerification.VerificationRecord
+			if err := opts.Provider.CreateTXTRecord(ctx, name, value); err != nil {
+				return nil, fmt.Errorf("creating verification record for %s: %w", domain, err)
+			}
+			created = true
+			step(VerifyStep{Kind: VerifyRecordCreated, Attempt: attempt, RecordName: name, RecordValue: value})
+		}
+
+		wait := policy.backoff(attempt)
+		step(VerifyStep{Kind: VerifyPending, Attempt: attempt, Err: verificationError, Wait: wait})
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, fmt.Errorf("domain %s not verified after %d attempts: %w", domain, attempt, verificationError)
+		}
+	}
+}
+
+// pending reports whether the error says what is required to verify the domain, rather than the request failing.
+func (e *VerificationError) pending() bool {
+	return e.TxtVerification.VerificationRecord != "" || len(e.NsVerification.IntendedNameservers) > 0
+}
@@ -0,0 +1,86 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+// verifyingProvider marks the domain as verified on the server once the TXT record is created.
+type verifyingProvider struct {
+	server  *zeittest.Server
+	domain  string
+	records map[string]string
+	err     error
+}
+
+func (p *verifyingProvider) CreateTXTRecord(ctx context.Context, name, value string) error {
+	if p.err != nil {
+		return p.err
+	}
+	p.records[name] = value
+	p.server.SetVerified("", p.domain, true)
+	return nil
+}
+
+func TestClient_EnsureVerified(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	added := server.AddDomain("", "example.com")
+	server.SetVerified("", "example.com", false)
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	provider := &verifyingProvider{server: server, domain: "example.com", records: make(map[string]string)}
+	var steps []VerifyStepKind
+	domain, err := client.EnsureVerified(context.Background(), "example.com", VerifyOptions{
+		Provider:    provider,
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+		OnStep: func(step VerifyStep) {
+			steps = append(steps, step.Kind)
+			if step.Kind == VerifyPending {
+				a.NotNil(step.Err, "pending steps should have the verification error")
+			}
+		},
+	})
+	a.Nil(err, "Error should be nil")
+	a.True(domain.Verified)
+	a.Equal(map[string]string{"_now.example.com": "zeittest-verification=" + added.Id}, provider.records)
+	a.Equal([]VerifyStepKind{VerifyAttempt, VerifyRecordCreated, VerifyPending, VerifyAttempt, VerifyVerified}, steps)
+}
+
+func TestClient_EnsureVerified_Timeout(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	server.SetVerified("", "example.com", false)
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	attempts := 0
+	_, err := client.EnsureVerified(context.Background(), "example.com", VerifyOptions{
+		Timeout:     50 * time.Millisecond,
+		MinInterval: time.Millisecond,
+		MaxInterval: 5 * time.Millisecond,
+		OnStep: func(step VerifyStep) {
+			if step.Kind == VerifyAttempt {
+				attempts++
+			}
+		},
+	})
+	var verificationError *VerificationError
+	a.True(errors.As(err, &verificationError), "should wrap the last verification error")
+	a.True(attempts > 1, "should poll until the timeout")
+
+	provider := &verifyingProvider{err: errors.New("provider unavailable")}
+	_, err = client.EnsureVerified(context.Background(), "example.com", VerifyOptions{Provider: provider})
+	a.True(errors.Is(err, provider.err), "should return the provider error")
+
+	_, err = client.EnsureVerified(context.Background(), "missing.com", VerifyOptions{})
+	a.True(errors.Is(err, ErrNotFound), "should return errors other than verification errors")
+}
@@ -0,0 +1,103 @@
+package zeit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Defaults used by WaitForDeployment when WaitOptions leaves them unset.
+const (
+	DefaultWaitTimeout     = 30 * time.Minute
+	DefaultWaitMinInterval = 2 * time.Second
+	DefaultWaitMaxInterval = 30 * time.Second
+)
+
+// WaitOptions changes how WaitForDeployment waits for a deployment.
+type WaitOptions struct {
+	// Timeout is how long to wait, zero uses DefaultWaitTimeout.
+	Timeout time.Duration
+	// MinInterval and MaxInterval bound the backoff between polls, zero uses DefaultWaitMinInterval and
+	// DefaultWaitMaxInterval. The backoff starts again from MinInterval whenever the state changes.
+	MinInterval time.Duration
+	MaxInterval time.Duration
+	// OnStateChange is called with the deployment whenever its state changes and with the state it had before, which
+	// is empty the first time it is called.
+	OnStateChange func(previous DeploymentState, deployment *Deployment)
+}
+
+// DeploymentError is returned by WaitForDeployment when a deployment fails or is canceled.
+type DeploymentError struct {
+	Deployment *Deployment
+	State      DeploymentState
+	// Code and Message are why the deployment failed, as reported by the API.
+	Code    string
+	Message string
+}
+
+func (e *DeploymentError) Error() string {
+	message := fmt.Sprintf("deployment %s %s", e.Deployment.Id, e.State)
+	if e.Code != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Code)
+	}
+	if e.Message != "" {
+		message = fmt.Sprintf("%s: %s", message, e.Message)
+	}
+	return message
+}
+
+// WaitForDeployment will poll the deployment with exponential backoff until it is READY, which is returned. If the
+// deployment fails or is canceled a *DeploymentError is returned. If it isn't ready before the timeout, or the context
+// is done, the last deployment read is returned along with an error wrapping the error of the context.
+func (c Client) WaitForDeployment(ctx context.Context, id string, opts WaitOptions) (*Deployment, error) {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+	policy := RetryPolicy{MinBackoff: opts.MinInterval, MaxBackoff: opts.MaxInterval}
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = DefaultWaitMinInterval
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = DefaultWaitMaxInterval
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var last *Deployment
+	var state DeploymentState
+	for attempt := 1; ; attempt++ {
+		deployment, err := c.GetDeploymentContext(ctx, id)
+		if err != nil {
+			if ctx.Err() != nil && last != nil {
+				return last, fmt.Errorf("deployment %s still %s: %w", id, state, ctx.Err())
+			}
+			return last, err
+		}
+		last = deployment
+
+		if deployment.State != state {
+			if opts.OnStateChange != nil {
+				opts.OnStateChange(state, deployment)
+			}
+			state = deployment.State
+			attempt = 1
+		}
+		switch state {
+		case DeploymentStateReady:
+			return deployment, nil
+		case DeploymentStateError, DeploymentStateCanceled:
+			return deployment, &DeploymentError{
+				Deployment: deployment,
+				State:      state,
+				Code:       deployment.ErrorCode,
+				Message:    deployment.ErrorMessage,
+			}
+		}
+
+		if err := sleepContext(ctx, policy.backoff(attempt)); err != nil {
+			return last, fmt.Errorf("deployment %s still %s: %w", id, state, err)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package zeit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_WaitForDeployment(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+	added := server.AddDeployment("", zeittest.Deployment{Name: "web", State: "INITIALIZING"})
+
+	// every time a state is seen the deployment moves on to the next one
+	next := map[DeploymentState]string{
+		DeploymentStateInitializing: "BUILDING",
+		DeploymentStateBuilding:     "DEPLOYING",
+		DeploymentStateDeploying:    "READY",
+	}
+	var transitions []DeploymentState
+	deployment, err := client.WaitForDeployment(context.Background(), added.Id, WaitOptions{
+		MinInterval: time.Millisecond,
+		MaxInterval: time.Millisecond,
+		OnStateChange: func(previous DeploymentState, deployment *Deployment) {
+			if len(transitions) > 0 {
+				a.Equal(transitions[len(transitions)-1], previous)
+			} else {
+				a.Empty(previous)
+			}
+			transitions = append(transitions, deployment.State)
+			if state, ok := next[deployment.State]; ok {
+				server.SetDeploymentState("", added.Id, state, "", "")
+			}
+		},
+	})
+	a.Nil(err, "Error should be nil")
+	a.Equal(DeploymentStateReady, deployment.State)
+	a.Equal([]DeploymentState{
+		DeploymentStateInitializing,
+		DeploymentStateBuilding,
+		DeploymentStateDeploying,
+		DeploymentStateReady,
+	}, transitions)
+}
+
+func TestClient_WaitForDeploymentError(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+	opts := WaitOptions{MinInterval: time.Millisecond, MaxInterval: 5 * time.Millisecond}
+
+	failed := server.AddDeployment("", zeittest.Deployment{Name: "web"})
+	server.SetDeploymentState("", failed.Id, "ERROR", "build_failed", "The build failed")
+	deployment, err := client.WaitForDeployment(context.Background(), failed.Id, opts)
+	var deploymentError *DeploymentError
+	a.True(errors.As(err, &deploymentError), "failed deployments should return a DeploymentError")
+	a.Equal(DeploymentStateError, deploymentError.State)
+	a.Equal("build_failed", deploymentError.Code)
+	a.Equal("The build failed", deploymentError.Message)
+	a.Equal(failed.Id, deployment.Id, "the failed deployment should be returned")
+	a.EqualError(err, "deployment "+failed.Id+" ERROR: build_failed: The build failed")
+
+	canceled := server.AddDeployment("", zeittest.Deployment{Name: "web", State: "CANCELED"})
+	_, err = client.WaitForDeployment(context.Background(), canceled.Id, opts)
+	a.True(errors.As(err, &deploymentError))
+	a.Equal(DeploymentStateCanceled, deploymentError.State)
+
+	building := server.AddDeployment("", zeittest.Deployment{Name: "web", State: "BUILDING"})
+	opts.Timeout = 20 * time.Millisecond
+	deployment, err = client.WaitForDeployment(context.Background(), building.Id, opts)
+	a.True(errors.Is(err, context.DeadlineExceeded), "should time out")
+	a.Equal(DeploymentStateBuilding, deployment.State, "the last deployment read should be returned")
+
+	_, err = client.WaitForDeployment(context.Background(), "dpl_missing", opts)
+	a.True(errors.Is(err, ErrNotFound))
+}
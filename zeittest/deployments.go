@@ -0,0 +1,475 @@
+package zeittest
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Deployment is a deployment stored by the Server.
+type Deployment struct {
+	Id           string
+	Name         string
+	Url          string
+	ProjectId    string
+	State        string
+	Target       string
+	Meta         map[string]string
+	ErrorCode    string
+	ErrorMessage string
+	Created      int64
+	Files        []File
+	Events       []Event
+}
+
+// Event is a build event of a deployment, sent by the event stream of the deployment.
+type Event struct {
+	// Type is command, stdout, stderr, exit or deployment-state.
+	Type string
+	// Created is the time of the event in milliseconds, if zero it is set by the server. Events can share a time.
+	Created int64
+	Text    string
+	// State is the state of the deployment for deployment-state events.
+	State string
+}
+
+// File is a file or directory of a deployment's file tree.
+type File struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Uid      string `json:"uid,omitempty"`
+	Mode     int    `json:"mode"`
+	Children []File `json:"children,omitempty"`
+}
+
+// AddDeployment will add a deployment to the personal account, or to the team if team isn't empty. The id, url and
+// creation time are set by the server and the state defaults to READY. The team is added if it doesn't exist.
+func (s *Server) AddDeployment(team string, deployment Deployment) Deployment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sc, ok := s.scopes[team]
+	if !ok {
+		sc = newScope()
+		s.scopes[team] = sc
+	}
+	return *s.addDeployment(sc, deployment)
+}
+
+// SetDeploymentState changes the state of a deployment, along with the error reported when the state is ERROR. A
+// deployment-state event is added to the events of the deployment.
+func (s *Server) SetDeploymentState(team, id, state, errorCode, errorMessage string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sc, ok := s.scopes[team]; ok {
+		if deployment, ok := sc.deployments[id]; ok {
+			deployment.State = state
+			deployment.ErrorCode = errorCode
+			deployment.ErrorMessage = errorMessage
+			deployment.Events = append(deployment.Events, Event{
+				Type:    "deployment-state",
+				Created: s.now(),
+				State:   state,
+			})
+		}
+	}
+}
+
+// AddDeploymentEvent will add an event to the deployment, it is sent to any stream following the events of the
+// deployment.
+func (s *Server) AddDeploymentEvent(team, id string, event Event) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sc, ok := s.scopes[team]; ok {
+		if deployment, ok := sc.deployments[id]; ok {
+			if event.Created == 0 {
+				event.Created = s.now()
+			} else if event.Created > s.clock {
+				// keep later events after this one
+				s.clock = event.Created
+			}
+			deployment.Events = append(deployment.Events, event)
+		}
+	}
+}
+
+// DropEventStreams will cut the connection of every event stream once it has sent n events, as if the connection
+// dropped. Zero, the default, never cuts them.
+func (s *Server) DropEventStreams(n int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.dropEvents = n
+}
+
+// Deployments returns every deployment of the personal account, or of the team if team isn't empty, newest first.
+func (s *Server) Deployments(team string) []Deployment {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var deployments []Deployment
+	if sc, ok := s.scopes[team]; ok {
+		for _, deployment := range sc.sortedDeployments() {
+			deployments = append(deployments, *deployment)
+		}
+	}
+	return deployments
+}
+
+// AddFile will store the contents of a file as if it had been uploaded, so deployments including it don't need to
+// upload it again. The hex encoded SHA1 digest of the file is returned.
+func (s *Server) AddFile(team string, data []byte) string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sc, ok := s.scopes[team]
+	if !ok {
+		sc = newScope()
+		s.scopes[team] = sc
+	}
+	digest := sha1.Sum(data)
+	sha := hex.EncodeToString(digest[:])
+	sc.files[sha] = data
+	return sha
+}
+
+// File returns the contents of the uploaded file with the hex encoded SHA1 digest.
+func (s *Server) File(team, sha string) ([]byte, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sc, ok := s.scopes[team]; ok {
+		data, ok := sc.files[sha]
+		return data, ok
+	}
+	return nil, false
+}
+
+func (s *Server) addDeployment(sc *scope, deployment Deployment) *Deployment {
+	deployment.Id = s.id("dpl")
+	if deployment.Name == "" {
+		deployment.Name = "zeittest"
+	}
+	deployment.Url = fmt.Sprintf("%s-%s.now.sh", deployment.Name, strings.TrimPrefix(deployment.Id, "dpl_"))
+	if deployment.State == "" {
+		deployment.State = "READY"
+	}
+	deployment.Created = s.now()
+	sc.deployments[deployment.Id] = &deployment
+	return &deployment
+}
+
+// sortedDeployments returns the deployments of the scope, newest first.
+func (sc *scope) sortedDeployments() []*Deployment {
+	deployments := make([]*Deployment, 0, len(sc.deployments))
+	for _, deployment := range sc.deployments {
+		deployments = append(deployments, deployment)
+	}
+	sort.Slice(deployments, func(i, j int) bool {
+		return deployments[i].Created > deployments[j].Created
+	})
+	return deployments
+}
+
+// routeDeployments will handle requests to the deployments endpoints, segments is the path after now/deployments.
+func (s *Server) routeDeployments(w http.ResponseWriter, r *http.Request, sc *scope, version string, segments []string) bool {
+	switch {
+	case version == "v5" && len(segments) == 0 && r.Method == http.MethodGet:
+		s.listDeployments(w, r, sc)
+	case version == "v12" && len(segments) == 0 && r.Method == http.MethodPost:
+		s.createDeployment(w, r, sc)
+	case version == "v9" && len(segments) == 1 && r.Method == http.MethodGet:
+		s.getDeployment(w, sc, segments[0])
+	case version == "v9" && len(segments) == 1 && r.Method == http.MethodDelete:
+		s.deleteDeployment(w, sc, segments[0])
+	case version == "v5" && len(segments) == 2 && segments[1] == "files" && r.Method == http.MethodGet:
+		s.deploymentFiles(w, sc, segments[0])
+	default:
+		return false
+	}
+	return true
+}
+
+func (s *Server) listDeployments(w http.ResponseWriter, r *http.Request, sc *scope) {
+	query := r.URL.Query()
+	states := make(map[string]bool)
+	for _, state := range strings.Split(query.Get("state"), ",") {
+		if state != "" {
+			states[state] = true
+		}
+	}
+
+	var deployments []*Deployment
+	for _, deployment := range sc.sortedDeployments() {
+		if projectId := query.Get("projectId"); projectId != "" && deployment.ProjectId != projectId {
+			continue
+		}
+		if len(states) > 0 && !states[deployment.State] {
+			continue
+		}
+		deployments = append(deployments, deployment)
+	}
+	created := make([]int64, len(deployments))
+	for i, deployment := range deployments {
+		created[i] = deployment.Created
+	}
+
+	start, end, next := s.paginate(r, created)
+	items := make([]map[string]interface{}, 0, end-start)
+	for _, deployment := range deployments[start:end] {
+		items = append(items, map[string]interface{}{
+			"uid":     deployment.Id,
+			"name":    deployment.Name,
+			"url":     deployment.Url,
+			"state":   deployment.State,
+			"target":  deployment.Target,
+			"meta":    deployment.Meta,
+			"created": deployment.Created,
+			"creator": map[string]interface{}{"uid": "usr_1"},
+		})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"deployments": items,
+		"pagination":  map[string]interface{}{"count": end - start, "next": next, "prev": nil},
+	})
+}
+
+func (s *Server) uploadFile(w http.ResponseWriter, r *http.Request, sc *scope) {
+	data, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid body", nil)
+		return
+	}
+	digest := sha1.Sum(data)
+	sha := hex.EncodeToString(digest[:])
+	if r.Header.Get("x-now-digest") != sha {
+		writeError(w, http.StatusBadRequest, "invalid_digest", "The x-now-digest header does not match the file", nil)
+		return
+	}
+	if r.Header.Get("x-now-size") != strconv.Itoa(len(data)) {
+		writeError(w, http.StatusBadRequest, "invalid_size", "The x-now-size header does not match the file", nil)
+		return
+	}
+	sc.files[sha] = data
+	writeJSON(w, http.StatusOK, map[string]interface{}{"urls": []string{}})
+}
+
+func (s *Server) createDeployment(w http.ResponseWriter, r *http.Request, sc *scope) {
+	parameters := struct {
+		Name  string `json:"name"`
+		Files []struct {
+			File string `json:"file"`
+			Sha  string `json:"sha"`
+			Size int    `json:"size"`
+			Mode int    `json:"mode"`
+		} `json:"files"`
+		Target string            `json:"target"`
+		Meta   map[string]string `json:"meta"`
+	}{}
+	if !decodeBody(w, r, &parameters) {
+		return
+	}
+	if parameters.Name == "" {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid request: missing required property `name`", nil)
+		return
+	}
+
+	missing := make([]string, 0)
+	root := &File{Type: "directory"}
+	for _, file := range parameters.Files {
+		if _, ok := sc.files[file.Sha]; !ok {
+			if !contains(missing, file.Sha) {
+				missing = append(missing, file.Sha)
+			}
+			continue
+		}
+		root.add(strings.Split(file.File, "/"), file.Sha, file.Mode)
+	}
+	if len(missing) > 0 {
+		writeError(w, http.StatusBadRequest, "missing_files", "Missing files", map[string]interface{}{
+			"missing": missing,
+		})
+		return
+	}
+
+	deployment := s.addDeployment(sc, Deployment{
+		Name:   parameters.Name,
+		State:  "INITIALIZING",
+		Target: parameters.Target,
+		Meta:   parameters.Meta,
+		Files:  root.Children,
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id":         deployment.Id,
+		"name":       deployment.Name,
+		"url":        deployment.Url,
+		"readyState": deployment.State,
+		"target":     deployment.Target,
+		"meta":       deployment.Meta,
+		"createdAt":  deployment.Created,
+		"ownerId":    "usr_1",
+	})
+}
+
+// isEventStream reports whether the request is for the event stream of a deployment.
+func isEventStream(r *http.Request, segments []string) bool {
+	return r.Method == http.MethodGet && len(segments) == 5 && segments[0] == "v2" && segments[1] == "now" &&
+		segments[2] == "deployments" && segments[4] == "events"
+}
+
+// streamEvents will write the events of the deployment as newline delimited json, starting from the since query
+// parameter. If the follow parameter is 1 the stream is kept open and new events are sent until the deployment is
+// ready, has failed or is canceled. The lock must not be held.
+func (s *Server) streamEvents(w http.ResponseWriter, r *http.Request, sc *scope, id string) {
+	query := r.URL.Query()
+	since, _ := strconv.ParseInt(query.Get("since"), 10, 64)
+	follow := query.Get("follow") == "1"
+
+	s.mutex.Lock()
+	_, ok := sc.deployments[id]
+	drop := s.dropEvents
+	s.mutex.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The deployment was not found", nil)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+	ticker := time.NewTicker(5 * time.Millisecond)
+	defer ticker.Stop()
+	read, written := 0, 0
+	for {
+		s.mutex.Lock()
+		var events []Event
+		finished := true
+		if deployment, ok := sc.deployments[id]; ok {
+			events = append(events, deployment.Events[read:]...)
+			finished = deployment.State == "READY" || deployment.State == "ERROR" || deployment.State == "CANCELED"
+		}
+		read += len(events)
+		s.mutex.Unlock()
+
+		for _, event := range events {
+			if event.Created < since {
+				continue
+			}
+			if drop > 0 && written == drop {
+				dropConnection(w)
+				return
+			}
+			payload := map[string]interface{}{"deploymentId": id, "date": event.Created}
+			if event.Text != "" {
+				payload["text"] = event.Text
+			}
+			if event.State != "" {
+				payload["info"] = map[string]interface{}{"readyState": event.State}
+			}
+			_ = encoder.Encode(map[string]interface{}{
+				"type":    event.Type,
+				"created": event.Created,
+				"payload": payload,
+			})
+			written++
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		if !follow || finished {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dropConnection closes the connection of the response without ending the body, so the client sees the connection
+// drop part way through the response.
+func dropConnection(w http.ResponseWriter) {
+	if flusher, ok := w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	if hijacker, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			_ = conn.Close()
+		}
+	}
+}
+
+// add will add the file at the path to the tree under f, creating any directories it is in.
+func (f *File) add(path []string, uid string, mode int) {
+	if len(path) == 1 {
+		f.Children = append(f.Children, File{Name: path[0], Type: "file", Uid: uid, Mode: mode})
+		return
+	}
+	for i := range f.Children {
+		if f.Children[i].Name == path[0] && f.Children[i].Type == "directory" {
+			f.Children[i].add(path[1:], uid, mode)
+			return
+		}
+	}
+	f.Children = append(f.Children, File{Name: path[0], Type: "directory", Mode: 040755})
+	f.Children[len(f.Children)-1].add(path[1:], uid, mode)
+}
+
+func (s *Server) getDeployment(w http.ResponseWriter, sc *scope, id string) {
+	deployment, ok := sc.deployments[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The deployment was not found", nil)
+		return
+	}
+	body := map[string]interface{}{
+		"id":         deployment.Id,
+		"name":       deployment.Name,
+		"url":        deployment.Url,
+		"readyState": deployment.State,
+		"target":     deployment.Target,
+		"meta":       deployment.Meta,
+		"createdAt":  deployment.Created,
+		"ownerId":    "usr_1",
+	}
+	if deployment.State == "ERROR" {
+		body["errorCode"] = deployment.ErrorCode
+		body["errorMessage"] = deployment.ErrorMessage
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+func (s *Server) deleteDeployment(w http.ResponseWriter, sc *scope, id string) {
+	if _, ok := sc.deployments[id]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The deployment was not found", nil)
+		return
+	}
+	delete(sc.deployments, id)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"uid": id, "state": "DELETED"})
+}
+
+func (s *Server) deploymentFiles(w http.ResponseWriter, sc *scope, id string) {
+	deployment, ok := sc.deployments[id]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The deployment was not found", nil)
+		return
+	}
+	files := deployment.Files
+	if files == nil {
+		files = []File{}
+	}
+	writeJSON(w, http.StatusOK, files)
+}
+
+// contains reports whether the value is in the list.
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
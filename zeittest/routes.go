@@ -0,0 +1,336 @@
+package zeittest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// price is the price of every domain sold by the server.
+const price = 20
+
+// route will handle the request if the path is one the server emulates, it returns false otherwise.
+func (s *Server) route(w http.ResponseWriter, r *http.Request, sc *scope, segments []string) bool {
+	if len(segments) == 3 && segments[1] == "now" && segments[2] == "files" && r.Method == http.MethodPost {
+		s.uploadFile(w, r, sc)
+		return true
+	}
+	if len(segments) >= 3 && segments[1] == "now" && segments[2] == "deployments" {
+		return s.routeDeployments(w, r, sc, segments[0], segments[3:])
+	}
+	if len(segments) < 2 || segments[1] != "domains" {
+		return false
+	}
+	version, segments := segments[0], segments[2:]
+
+	switch {
+	case version == "v4" && len(segments) == 0 && r.Method == http.MethodGet:
+		s.listDomains(w, r, sc)
+	case version == "v4" && len(segments) == 0 && r.Method == http.MethodPost:
+		s.createDomain(w, r, sc)
+	case version == "v4" && len(segments) == 1 && segments[0] == "status" && r.Method == http.MethodGet:
+		s.domainStatus(w, r)
+	case version == "v4" && len(segments) == 1 && segments[0] == "price" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]interface{}{"price": price, "period": 1})
+	case version == "v4" && len(segments) == 1 && segments[0] == "buy" && r.Method == http.MethodPost:
+		s.buyDomain(w, r, sc)
+	case version == "v4" && len(segments) == 1 && r.Method == http.MethodGet:
+		s.getDomain(w, sc, segments[0])
+	case version == "v4" && len(segments) == 1 && r.Method == http.MethodDelete:
+		s.removeDomain(w, sc, segments[0])
+	case version == "v4" && len(segments) == 2 && segments[1] == "verify" && r.Method == http.MethodPost:
+		s.verifyDomain(w, sc, segments[0])
+	case len(segments) == 2 && segments[1] == "records" && r.Method == http.MethodGet:
+		s.listRecords(w, r, sc, segments[0])
+	case version == "v2" && len(segments) == 2 && segments[1] == "records" && r.Method == http.MethodPost:
+		s.createRecord(w, r, sc, segments[0])
+	case version == "v2" && len(segments) == 3 && segments[1] == "records" && r.Method == http.MethodDelete:
+		s.removeRecord(w, sc, segments[0], segments[2])
+	case version == "v2" && len(segments) == 3 && segments[1] == "records" && r.Method == http.MethodPatch:
+		s.updateRecord(w, r, sc, segments[0], segments[2])
+	default:
+		return false
+	}
+	return true
+}
+
+// paginate returns the range of items to include in a page and the cursor of the next page, created must be sorted
+// newest first. The limit, since and until query parameters are applied the same way as the API.
+func (s *Server) paginate(r *http.Request, created []int64) (int, int, interface{}) {
+	query := r.URL.Query()
+	limit := s.pageSize
+	if l, err := strconv.Atoi(query.Get("limit")); err == nil && l > 0 {
+		limit = l
+	}
+	until, _ := strconv.ParseInt(query.Get("until"), 10, 64)
+	since, _ := strconv.ParseInt(query.Get("since"), 10, 64)
+
+	start := 0
+	for start < len(created) && until > 0 && created[start] >= until {
+		start++
+	}
+	end := start
+	for end < len(created) && end-start < limit && created[end] > since {
+		end++
+	}
+
+	var next interface{}
+	if end < len(created) && created[end] > since {
+		next = created[end-1]
+	}
+	return start, end, next
+}
+
+// decodeBody decodes the json body of the request into v, writing a 400 response if it is invalid.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, "bad_request", "Invalid JSON body", nil)
+		return false
+	}
+	return true
+}
+
+func (s *Server) listDomains(w http.ResponseWriter, r *http.Request, sc *scope) {
+	domains := make([]*Domain, 0, len(sc.domains))
+	for _, domain := range sc.domains {
+		domains = append(domains, domain)
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].CreatedAt > domains[j].CreatedAt
+	})
+	created := make([]int64, len(domains))
+	for i, domain := range domains {
+		created[i] = domain.CreatedAt
+	}
+
+	start, end, next := s.paginate(r, created)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"domains":    domains[start:end],
+		"pagination": map[string]interface{}{"count": end - start, "next": next, "prev": nil},
+	})
+}
+
+func (s *Server) createDomain(w http.ResponseWriter, r *http.Request, sc *scope) {
+	parameters := struct {
+		Name   string `json:"name"`
+		Method string `json:"method"`
+	}{}
+	if !decodeBody(w, r, &parameters) {
+		return
+	}
+	if parameters.Name == "" {
+		writeError(w, http.StatusBadRequest, "missing_name", "The domain name is missing", nil)
+		return
+	}
+	if _, ok := sc.domains[parameters.Name]; ok {
+		writeError(w, http.StatusConflict, "conflict", "The domain already exists", map[string]interface{}{
+			"name": parameters.Name,
+		})
+		return
+	}
+	domain := s.addDomain(sc, parameters.Name)
+	domain.Verified = false
+	domain.ServiceType = "external"
+	writeJSON(w, http.StatusOK, map[string]interface{}{"domain": domain})
+}
+
+func (s *Server) domainStatus(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	available := true
+	for _, sc := range s.scopes {
+		if _, ok := sc.domains[name]; ok {
+			available = false
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"available": available})
+}
+
+func (s *Server) buyDomain(w http.ResponseWriter, r *http.Request, sc *scope) {
+	parameters := struct {
+		Name          string `json:"name"`
+		ExpectedPrice int    `json:"expectedPrice"`
+	}{}
+	if !decodeBody(w, r, &parameters) {
+		return
+	}
+	if parameters.ExpectedPrice != price {
+		writeError(w, http.StatusForbidden, "price_mismatch", "The expected price does not match the price", nil)
+		return
+	}
+	for _, other := range s.scopes {
+		if _, ok := other.domains[parameters.Name]; ok {
+			writeError(w, http.StatusConflict, "not_available", "The domain is not available", nil)
+			return
+		}
+	}
+	domain := s.addDomain(sc, parameters.Name)
+	domain.BoughtAt = domain.CreatedAt
+	writeJSON(w, http.StatusOK, map[string]interface{}{"uid": domain.Id})
+}
+
+func (s *Server) getDomain(w http.ResponseWriter, sc *scope, name string) {
+	domain, ok := sc.domains[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The domain was not found", map[string]interface{}{
+			"name": name,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"domain": domain})
+}
+
+func (s *Server) removeDomain(w http.ResponseWriter, sc *scope, name string) {
+	domain, ok := sc.domains[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The domain was not found", map[string]interface{}{
+			"name": name,
+		})
+		return
+	}
+	delete(sc.domains, name)
+	delete(sc.records, name)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"uid": domain.Id})
+}
+
+func (s *Server) verifyDomain(w http.ResponseWriter, sc *scope, name string) {
+	domain, ok := sc.domains[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The domain was not found", map[string]interface{}{
+			"name": name,
+		})
+		return
+	}
+	if !domain.Verified {
+		writeError(w, http.StatusBadRequest, "verification_failed", "The domain couldn't be verified",
+			map[string]interface{}{
+				"name": name,
+				"nsVerification": map[string]interface{}{
+					"name":                name,
+					"nameservers":         []string{},
+					"intendedNameservers": domain.Nameservers,
+				},
+				"txtVerification": map[string]interface{}{
+					"name":               name,
+					"values":             []string{},
+					"verificationRecord": verificationRecord(domain),
+				},
+			})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"domain": domain})
+}
+
+// verificationRecord returns the value of the TXT record that verifies the domain.
+func verificationRecord(domain *Domain) string {
+	return "zeittest-verification=" + domain.Id
+}
+
+func (s *Server) listRecords(w http.ResponseWriter, r *http.Request, sc *scope, name string) {
+	if _, ok := sc.domains[name]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The domain was not found", nil)
+		return
+	}
+	records := append([]*Record{}, sc.records[name]...)
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].Created > records[j].Created
+	})
+	created := make([]int64, len(records))
+	for i, record := range records {
+		created[i] = record.Created
+	}
+
+	start, end, next := s.paginate(r, created)
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"records":    records[start:end],
+		"pagination": map[string]interface{}{"count": end - start, "next": next, "prev": nil},
+	})
+}
+
+func (s *Server) createRecord(w http.ResponseWriter, r *http.Request, sc *scope, name string) {
+	if _, ok := sc.domains[name]; !ok {
+		writeError(w, http.StatusNotFound, "not_found", "The domain was not found", nil)
+		return
+	}
+	record := Record{}
+	if !decodeBody(w, r, &record) {
+		return
+	}
+	if record.Type == "" || record.Value == "" {
+		writeError(w, http.StatusBadRequest, "invalid_value", "The record type and value are required", nil)
+		return
+	}
+	for _, existing := range sc.records[name] {
+		if existing.Name == record.Name && existing.Type == record.Type && existing.Value == record.Value {
+			writeError(w, http.StatusConflict, "conflict_record", "The record already exists",
+				map[string]interface{}{"oldId": existing.Id})
+			return
+		}
+	}
+	if cnameConflict(sc.records[name], record.Name, record.Type, "") {
+		writeError(w, http.StatusBadRequest, "cname_conflict", "A CNAME record can't share its name with other records", nil)
+		return
+	}
+	created := s.addRecord(sc, name, record)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"uid": created.Id})
+}
+
+// cnameConflict reports whether a record with the name and type can't be added to the records because a CNAME record
+// would share its name with another record. The record with the id is left out, so a record can be updated in place.
+func cnameConflict(records []*Record, name, recordType, id string) bool {
+	for _, existing := range records {
+		if existing.Id == id || existing.Name != name {
+			continue
+		}
+		if recordType == "CNAME" || existing.Type == "CNAME" {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) removeRecord(w http.ResponseWriter, sc *scope, name, id string) {
+	records := sc.records[name]
+	for i, record := range records {
+		if record.Id == id {
+			sc.records[name] = append(records[:i:i], records[i+1:]...)
+			writeJSON(w, http.StatusOK, map[string]interface{}{})
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "not_found", "The record was not found", nil)
+}
+
+func (s *Server) updateRecord(w http.ResponseWriter, r *http.Request, sc *scope, name, id string) {
+	update := Record{}
+	if !decodeBody(w, r, &update) {
+		return
+	}
+	for _, record := range sc.records[name] {
+		if record.Id == id {
+			recordType := record.Type
+			if update.Type != "" {
+				recordType = update.Type
+			}
+			if cnameConflict(sc.records[name], update.Name, recordType, id) {
+				writeError(w, http.StatusBadRequest, "cname_conflict",
+					"A CNAME record can't share its name with other records", nil)
+				return
+			}
+			record.Name = update.Name
+			if update.Type != "" {
+				record.Type = update.Type
+			}
+			if update.Value != "" {
+				record.Value = update.Value
+			}
+			if update.TTL != 0 {
+				record.TTL = update.TTL
+			}
+			record.Updated = s.now()
+			writeJSON(w, http.StatusOK, record)
+			return
+		}
+	}
+	writeError(w, http.StatusNotFound, "not_found", "The record was not found", nil)
+}
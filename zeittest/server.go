@@ -0,0 +1,342 @@
+// Package zeittest provides an in-memory stand-in for the ZEIT API so code using the zeit client can be tested without
+// network access.
+//
+//	server := zeittest.NewServer()
+//	defer server.Close()
+//	server.AddDomain("", "example.com")
+//
+//	client := zeit.NewClient(server.Token, zeit.WithBaseURL(server.URL))
+//	records, err := client.ListDNSRecords("example.com")
+//
+// The server emulates the Domains, DNS and Deployments endpoints, including authentication, team scoping, conflicts,
+// rate limit headers and pagination. It does not import the zeit package so it can also be used by the package's own
+// tests.
+package zeittest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// servers counts the servers that have been started so each is given a different token, clients share their rate limit
+// by token so this keeps the rate limit of one server from affecting clients of another.
+var servers int64
+
+// DefaultPageSize is the number of items returned per page when a request doesn't set a limit.
+const DefaultPageSize = 20
+
+// Domain is a domain stored by the Server.
+type Domain struct {
+	Id          string   `json:"id"`
+	Name        string   `json:"name"`
+	ServiceType string   `json:"serviceType"`
+	Verified    bool     `json:"verified"`
+	Nameservers []string `json:"nameservers"`
+	CreatedAt   int64    `json:"createdAt"`
+	BoughtAt    int64    `json:"boughtAt,omitempty"`
+}
+
+// Record is a DNS record stored by the Server.
+type Record struct {
+	Id      string `json:"id"`
+	Slug    string `json:"slug"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	TTL     int    `json:"ttl,omitempty"`
+	Creator string `json:"creator"`
+	Created int64  `json:"created"`
+	Updated int64  `json:"updated"`
+}
+
+// scope is the state of the personal account or of a team.
+type scope struct {
+	domains     map[string]*Domain
+	records     map[string][]*Record
+	deployments map[string]*Deployment
+	files       map[string][]byte
+}
+
+func newScope() *scope {
+	return &scope{
+		domains:     make(map[string]*Domain),
+		records:     make(map[string][]*Record),
+		deployments: make(map[string]*Deployment),
+		files:       make(map[string][]byte),
+	}
+}
+
+// Server is an httptest.Server emulating the ZEIT API. Its methods can be used to set up and inspect the state of the
+// server while it is running, they are safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	// Token is the bearer token the server accepts, requests with any other token are rejected with 403.
+	Token string
+
+	mutex     sync.Mutex
+	scopes    map[string]*scope
+	nextId    int
+	clock     int64
+	pageSize  int
+	requests  int
+	rateLimit struct {
+		limit     int
+		window    time.Duration
+		remaining int
+		reset     time.Time
+	}
+	handlers   map[string]http.HandlerFunc
+	dropEvents int
+}
+
+// NewServer starts a server with no domains. Every server accepts a different token, see Token.
+func NewServer() *Server {
+	s := &Server{
+		Token:    fmt.Sprintf("zeittest-token-%d", atomic.AddInt64(&servers, 1)),
+		scopes:   map[string]*scope{"": newScope()},
+		clock:    time.Now().UnixNano() / int64(time.Millisecond),
+		pageSize: DefaultPageSize,
+		handlers: make(map[string]http.HandlerFunc),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// AddTeam will allow requests scoped to the team id.
+func (s *Server) AddTeam(team string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if _, ok := s.scopes[team]; !ok {
+		s.scopes[team] = newScope()
+	}
+}
+
+// AddDomain will add a verified domain to the personal account, or to the team if team isn't empty. The team is added
+// if it doesn't exist.
+func (s *Server) AddDomain(team, name string) Domain {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sc, ok := s.scopes[team]
+	if !ok {
+		sc = newScope()
+		s.scopes[team] = sc
+	}
+	return *s.addDomain(sc, name)
+}
+
+// AddRecord will add a record to a domain added with AddDomain and return its id.
+func (s *Server) AddRecord(team, domain string, record Record) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sc, ok := s.scopes[team]
+	if !ok || sc.domains[domain] == nil {
+		return "", fmt.Errorf("domain %s does not exist", domain)
+	}
+	return s.addRecord(sc, domain, record).Id, nil
+}
+
+// Domains returns every domain of the personal account or team.
+func (s *Server) Domains(team string) []Domain {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var domains []Domain
+	if sc, ok := s.scopes[team]; ok {
+		for _, domain := range sc.domains {
+			domains = append(domains, *domain)
+		}
+	}
+	sort.Slice(domains, func(i, j int) bool {
+		return domains[i].CreatedAt > domains[j].CreatedAt
+	})
+	return domains
+}
+
+// Records returns every record of the domain.
+func (s *Server) Records(team, domain string) []Record {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var records []Record
+	if sc, ok := s.scopes[team]; ok {
+		for _, record := range sc.records[domain] {
+			records = append(records, *record)
+		}
+	}
+	return records
+}
+
+// SetPageSize changes the number of items returned per page when a request doesn't set a limit.
+func (s *Server) SetPageSize(pageSize int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.pageSize = pageSize
+}
+
+// SetRateLimit will allow limit requests per window, after which requests are rejected with 429 until the window
+// resets. A limit of zero disables rate limiting, which is the default.
+func (s *Server) SetRateLimit(limit int, window time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.rateLimit.limit = limit
+	s.rateLimit.window = window
+	s.rateLimit.remaining = limit
+	s.rateLimit.reset = time.Now().Add(window)
+}
+
+// Requests returns the number of requests the server has received.
+func (s *Server) Requests() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.requests
+}
+
+// Handle will serve requests with the method and path, relative to the root of the server such as
+// "GET /v4/domains", with handler instead of the built in emulation. It can be used to inject failures or emulate
+// endpoints the server doesn't support. Authentication and rate limiting still apply.
+func (s *Server) Handle(method, path string, handler http.HandlerFunc) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.handlers[method+" "+path] = handler
+}
+
+// now returns a unique timestamp in milliseconds, later calls always return later timestamps so items can be ordered
+// by creation time.
+func (s *Server) now() int64 {
+	s.clock++
+	return s.clock
+}
+
+func (s *Server) id(prefix string) string {
+	s.nextId++
+	return fmt.Sprintf("%s_%d", prefix, s.nextId)
+}
+
+func (s *Server) addDomain(sc *scope, name string) *Domain {
+	domain := &Domain{
+		Id:          s.id("dom"),
+		Name:        name,
+		ServiceType: "zeit.world",
+		Verified:    true,
+		Nameservers: []string{"a.zeit-world.net", "b.zeit-world.co.uk"},
+		CreatedAt:   s.now(),
+	}
+	sc.domains[name] = domain
+	return domain
+}
+
+func (s *Server) addRecord(sc *scope, domain string, record Record) *Record {
+	record.Id = s.id("rec")
+	record.Slug = fmt.Sprintf("%s-%s-%s", record.Name, domain, record.Type)
+	record.Created = s.now()
+	record.Updated = record.Created
+	if record.Creator == "" {
+		record.Creator = "system"
+	}
+	sc.records[domain] = append(sc.records[domain], &record)
+	return &record
+}
+
+// writeJSON writes v as the json body of the response.
+func writeJSON(w http.ResponseWriter, statusCode int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error response in the format used by the API, any extra fields are added to the error object.
+func writeError(w http.ResponseWriter, statusCode int, code, message string, extra map[string]interface{}) {
+	body := map[string]interface{}{"code": code, "message": message}
+	for key, value := range extra {
+		body[key] = value
+	}
+	writeJSON(w, statusCode, map[string]interface{}{"error": body})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.requests++
+	w.Header().Set("X-Now-Id", s.id("req"))
+
+	if r.Header.Get("Authorization") != "Bearer "+s.Token {
+		s.mutex.Unlock()
+		writeError(w, http.StatusForbidden, "forbidden", "Not authorized", nil)
+		return
+	}
+
+	if !s.takeRateLimit(w) {
+		s.mutex.Unlock()
+		return
+	}
+
+	team := r.URL.Query().Get("teamId")
+	sc, ok := s.scopes[team]
+	if !ok {
+		s.mutex.Unlock()
+		writeError(w, http.StatusForbidden, "forbidden", "Not authorized to access the team", nil)
+		return
+	}
+
+	if handler, ok := s.handlers[r.Method+" "+r.URL.Path]; ok {
+		s.mutex.Unlock()
+		handler(w, r)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if isEventStream(r, segments) {
+		// the stream is followed without holding the lock so other requests can change the deployment
+		s.mutex.Unlock()
+		s.streamEvents(w, r, sc, segments[3])
+		return
+	}
+	defer s.mutex.Unlock()
+
+	if !s.route(w, r, sc, segments) {
+		writeError(w, http.StatusNotFound, "not_found", "The requested endpoint does not exist", nil)
+	}
+}
+
+// takeRateLimit sets the rate limit headers and takes one request from the limit, if none remain a 429 response is
+// written and false is returned.
+func (s *Server) takeRateLimit(w http.ResponseWriter) bool {
+	if s.rateLimit.limit == 0 {
+		return true
+	}
+	if now := time.Now(); !now.Before(s.rateLimit.reset) {
+		s.rateLimit.remaining = s.rateLimit.limit
+		s.rateLimit.reset = now.Add(s.rateLimit.window)
+	}
+	reset := s.rateLimit.reset.Unix()
+	w.Header().Set("X-RateLimit-Limit", strconv.Itoa(s.rateLimit.limit))
+	w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset, 10))
+	if s.rateLimit.remaining == 0 {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		writeError(w, http.StatusTooManyRequests, "rate_limited", "Rate limit exceeded", map[string]interface{}{
+			"limit": map[string]interface{}{"remaining": 0, "reset": reset, "total": s.rateLimit.limit},
+		})
+		return false
+	}
+	s.rateLimit.remaining--
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(s.rateLimit.remaining))
+	return true
+}
+
+// SetVerified changes whether a domain is verified, verifying an unverified domain fails with the TXT record and
+// nameservers required to verify it.
+func (s *Server) SetVerified(team, name string, verified bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if sc, ok := s.scopes[team]; ok {
+		if domain, ok := sc.domains[name]; ok {
+			domain.Verified = verified
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package zeittest_test
+
+import (
+	"context"
+	"errors"
+	"github.com/kochie/zeit-api-go"
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func newClient(server *zeittest.Server, opts ...zeit.Option) *zeit.Client {
+	opts = append([]zeit.Option{zeit.WithBaseURL(server.URL)}, opts...)
+	return zeit.NewClient(server.Token, opts...)
+}
+
+func TestServer_Authentication(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+
+	client := zeit.NewClient("wrong-token", zeit.WithBaseURL(server.URL))
+	_, err := client.ListAllDomains()
+	a.True(errors.Is(err, zeit.ErrForbidden), "invalid tokens should be rejected")
+}
+
+func TestServer_DNSRecords(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	client := newClient(server)
+
+	uid, err := client.CreateDNSRecord("example.com", &zeit.Record{Name: "www", Type: zeit.RecordTypeA, Value: "1.1.1.1"})
+	a.Nil(err, "Error should be nil")
+	a.NotEmpty(uid, "uid should be returned")
+
+	_, err = client.CreateDNSRecord("example.com", &zeit.Record{Name: "www", Type: zeit.RecordTypeA, Value: "1.1.1.1"})
+	var conflictError *zeit.ConflictError
+	a.True(errors.As(err, &conflictError), "duplicate records should conflict")
+	a.Equal(uid, conflictError.OldId, "conflict should reference the existing record")
+
+	records, err := client.ListDNSRecords("example.com")
+	a.Nil(err, "Error should be nil")
+	a.Len(records, 1)
+	a.Equal("www", records[0].Name)
+	a.Equal("1.1.1.1", records[0].Value)
+
+	a.Nil(client.RemoveDNSRecord("example.com", uid), "record should be removed")
+	a.Empty(server.Records("", "example.com"), "record should be removed from the server")
+
+	err = client.RemoveDNSRecord("example.com", uid)
+	a.True(errors.Is(err, zeit.ErrNotFound), "removing a missing record should not be found")
+
+	_, err = client.ListDNSRecords("missing.com")
+	a.True(errors.Is(err, zeit.ErrNotFound), "missing domains should not be found")
+}
+
+func TestServer_Domains(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := newClient(server)
+
+	domain, err := client.AddDomain("example.com")
+	a.Nil(err, "Error should be nil")
+	a.Equal("example.com", domain.Name)
+
+	_, err = client.AddDomain("example.com")
+	a.True(errors.Is(err, zeit.ErrConflict), "adding a domain twice should conflict")
+
+	_, err = client.VerifyDomain("example.com")
+	var verificationError *zeit.VerificationError
+	a.True(errors.As(err, &verificationError), "added domains should not be verified")
+	a.NotEmpty(verificationError.TxtVerification.VerificationRecord, "should return the verification record")
+
+	server.SetVerified("", "example.com", true)
+	domain, err = client.VerifyDomain("example.com")
+	a.Nil(err, "Error should be nil")
+	a.True(domain.Verified)
+
+	available, err := client.CheckDomainAvailability("example.com")
+	a.Nil(err, "Error should be nil")
+	a.False(available, "added domains should not be available")
+
+	price, _, err := client.CheckDomainPrice("other.com")
+	a.Nil(err, "Error should be nil")
+	a.Nil(client.BuyDomain("other.com", price), "should buy the domain at the price")
+	a.True(errors.Is(client.BuyDomain("another.com", price+1), zeit.ErrForbidden), "wrong price should be forbidden")
+
+	_, err = client.RemoveDomain("example.com")
+	a.Nil(err, "Error should be nil")
+	_, err = client.GetDomain("example.com")
+	a.True(errors.Is(err, zeit.ErrNotFound), "removed domains should not be found")
+}
+
+func TestServer_Teams(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "personal.com")
+	server.AddDomain("team_1", "team.com")
+	client := newClient(server)
+
+	domains, err := client.ListAllDomains()
+	a.Nil(err, "Error should be nil")
+	a.Len(domains, 1)
+	a.Equal("personal.com", domains[0].Name)
+
+	domains, err = client.WithTeam("team_1").ListAllDomains()
+	a.Nil(err, "Error should be nil")
+	a.Len(domains, 1)
+	a.Equal("team.com", domains[0].Name)
+
+	_, err = client.ListAllDomainsContext(zeit.ContextWithTeam(context.Background(), "team_2"))
+	a.True(errors.Is(err, zeit.ErrForbidden), "unknown teams should be forbidden")
+}
+
+func TestServer_Deployments(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	added := server.AddDeployment("team_1", zeittest.Deployment{Name: "web"})
+	client := newClient(server)
+
+	deployments, err := client.ListDeployments()
+	a.Nil(err, "Error should be nil")
+	a.Empty(deployments, "deployments should be scoped to the team")
+
+	team := client.WithTeam("team_1")
+	server.SetDeploymentState("team_1", added.Id, "ERROR", "build_failed", "The build failed")
+	deployment, err := team.GetDeployment(added.Id)
+	a.Nil(err, "Error should be nil")
+	a.Equal(zeit.DeploymentStateError, deployment.State)
+	a.Equal("build_failed", deployment.ErrorCode)
+
+	a.Nil(team.DeleteDeployment(added.Id))
+	a.Empty(server.Deployments("team_1"))
+}
+
+func TestServer_Files(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	client := newClient(server)
+
+	sha, err := client.UploadFile([]byte("hello"))
+	a.Nil(err, "Error should be nil")
+	data, ok := server.File("", sha)
+	a.True(ok, "file should be stored by its digest")
+	a.Equal("hello", string(data))
+	_, ok = server.File("team_1", sha)
+	a.False(ok, "files should be scoped to the team")
+}
+
+func TestServer_Pagination(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.SetPageSize(3)
+	server.AddDomain("", "example.com")
+	for i := 0; i < 10; i++ {
+		_, err := server.AddRecord("", "example.com", zeittest.Record{Type: "TXT", Value: string(rune('a' + i))})
+		a.Nil(err)
+	}
+	client := newClient(server)
+
+	records, err := client.ListDNSRecords("example.com")
+	a.Nil(err, "Error should be nil")
+	a.Len(records, 10, "every page should be listed")
+	a.Equal(4, server.Requests(), "should request four pages")
+
+	seen := map[string]bool{}
+	for _, record := range records {
+		a.False(seen[record.Id], "records should not be repeated")
+		seen[record.Id] = true
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.SetRateLimit(2, time.Hour)
+	client := newClient(server, zeit.WithRateLimiter(zeit.FailFastRateLimiter{}))
+
+	for i := 0; i < 2; i++ {
+		_, err := client.ListAllDomains()
+		a.Nil(err, "Error should be nil")
+	}
+	a.Equal(0, client.RateLimit().Remaining, "should record the rate limit headers")
+
+	_, err := client.ListAllDomains()
+	a.True(errors.Is(err, zeit.ErrRateLimited), "should be rate limited")
+}
+
+func TestServer_Handle(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.Handle(http.MethodGet, "/v4/domains", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotImplemented)
+	})
+	client := newClient(server)
+
+	_, err := client.ListAllDomains()
+	var apiError *zeit.APIError
+	a.True(errors.As(err, &apiError))
+	a.Equal(http.StatusNotImplemented, apiError.StatusCode, "custom handlers should be used")
+}
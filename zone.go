@@ -0,0 +1,502 @@
+package zeit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxZoneTTL is the largest TTL allowed in a zone file, RFC 2181 limits TTLs to 2^31 - 1 seconds.
+const maxZoneTTL = 1<<31 - 1
+
+// ZoneParseError is returned by ParseZoneFile when the zone file is invalid.
+type ZoneParseError struct {
+	Line    int
+	Message string
+}
+
+func (e *ZoneParseError) Error() string {
+	return fmt.Sprintf("zone file line %d: %s", e.Line, e.Message)
+}
+
+// ExportZone will render every DNS record of the domain as an RFC 1035 master file, also known as a BIND zone file.
+func (c Client) ExportZone(domain string) (io.Reader, error) {
+	return c.ExportZoneContext(context.Background(), domain)
+}
+
+// ExportZoneContext is the same as ExportZone but the requests are bound to the given context.
+func (c Client) ExportZoneContext(ctx context.Context, domain string) (io.Reader, error) {
+	records, err := c.ListDNSRecordsContext(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	buf := &bytes.Buffer{}
+	if err := WriteZone(buf, domain, records); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// WriteZone will write the records of the domain to w as an RFC 1035 master file. Record names are written relative to
+// the origin and every hostname in a record value is written fully qualified.
+func WriteZone(w io.Writer, domain string, records []Record) error {
+	bw := bufio.NewWriter(w)
+	origin := strings.TrimSuffix(domain, ".")
+	fmt.Fprintf(bw, "; %s exported from ZEIT\n", origin)
+	fmt.Fprintf(bw, "$ORIGIN %s.\n", origin)
+	for i := range records {
+		record := &records[i]
+		name := record.Name
+		if name == "" {
+			name = "@"
+		}
+		ttl := ""
+		if record.TTL > 0 {
+			ttl = strconv.Itoa(record.TTL)
+		}
+		fmt.Fprintf(bw, "%s\t%s\tIN\t%s\t%s\n", name, ttl, record.Type, zoneData(record))
+	}
+	return bw.Flush()
+}
+
+// zoneData returns the data of a record in the format used by zone files. Values that can't be parsed are written as
+// they are.
+func zoneData(r *Record) string {
+	switch r.Type {
+	case RecordTypeCNAME, RecordTypeALIAS:
+		return fullyQualified(r.Value)
+	case RecordTypeMX:
+		if mx, err := r.MX(); err == nil {
+			return fmt.Sprintf("%d %s", mx.Priority, fullyQualified(mx.Host))
+		}
+	case RecordTypeSRV:
+		if srv, err := r.SRV(); err == nil {
+			return fmt.Sprintf("%d %d %d %s", srv.Priority, srv.Weight, srv.Port, fullyQualified(srv.Target))
+		}
+	case RecordTypeCAA:
+		if caa, err := r.CAA(); err == nil {
+			return fmt.Sprintf("%d %s %s", caa.Flags, caa.Tag, zoneQuote(caa.Value))
+		}
+	case RecordTypeTXT:
+		if strings.HasPrefix(strings.TrimSpace(r.Value), `"`) {
+			return strings.TrimSpace(r.Value)
+		}
+		// a single string in a TXT record can't be longer than 255 characters
+		var chunks []string
+		for text := r.Value; ; text = text[maxTXTStringLength:] {
+			if len(text) <= maxTXTStringLength {
+				chunks = append(chunks, zoneQuote(text))
+				break
+			}
+			chunks = append(chunks, zoneQuote(text[:maxTXTStringLength]))
+		}
+		return strings.Join(chunks, " ")
+	}
+	return r.GetValue()
+}
+
+// fullyQualified adds the trailing dot to a hostname, the API stores hostnames without it.
+func fullyQualified(name string) string {
+	if name == "." || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+// zoneQuote returns s as a quoted string, escaping quotes and backslashes.
+func zoneQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c > '~':
+			fmt.Fprintf(&b, "\\%03d", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// zoneUnquote reverses zoneQuote, a token that isn't quoted is returned as is. Both \X and the decimal \DDD escapes
+// are supported.
+func zoneUnquote(token string) string {
+	if len(token) < 2 || token[0] != '"' || token[len(token)-1] != '"' {
+		return token
+	}
+	s := token[1 : len(token)-1]
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		if i+3 < len(s) && isDigits(s[i+1:i+4]) {
+			n, _ := strconv.Atoi(s[i+1 : i+4])
+			b.WriteByte(byte(n))
+			i += 3
+			continue
+		}
+		i++
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// zoneEntry is a single entry of a zone file, entries grouped with parentheses can span several lines.
+type zoneEntry struct {
+	// line is the line the entry starts on.
+	line int
+	// indented entries start with whitespace and use the owner of the previous entry.
+	indented bool
+	// tokens of the entry, quoted strings keep their quotes.
+	tokens []string
+}
+
+// tokenizeZone splits a zone file into entries, removing comments and joining lines grouped with parentheses.
+func tokenizeZone(data string) ([]zoneEntry, error) {
+	var entries []zoneEntry
+	entry := zoneEntry{line: 1}
+	line, depth := 1, 0
+	atLineStart := true
+	var token strings.Builder
+
+	endToken := func() {
+		if token.Len() > 0 {
+			entry.tokens = append(entry.tokens, token.String())
+			token.Reset()
+		}
+	}
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if atLineStart && depth == 0 && len(entry.tokens) == 0 {
+			entry.line = line
+			entry.indented = c == ' ' || c == '\t'
+		}
+		atLineStart = false
+
+		switch {
+		case c == ';':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '"':
+			endToken()
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				} else if data[i] == '\n' {
+					break
+				}
+			}
+			if i >= len(data) || data[i] != '"' {
+				return nil, &ZoneParseError{Line: line, Message: "unterminated quoted string"}
+			}
+			entry.tokens = append(entry.tokens, data[start:i+1])
+		case c == '(':
+			endToken()
+			depth++
+		case c == ')':
+			endToken()
+			if depth == 0 {
+				return nil, &ZoneParseError{Line: line, Message: "unexpected )"}
+			}
+			depth--
+		case c == '\n':
+			endToken()
+			if depth == 0 && len(entry.tokens) > 0 {
+				entries = append(entries, entry)
+				entry = zoneEntry{}
+			}
+			line++
+			atLineStart = true
+		case unicode.IsSpace(rune(c)):
+			endToken()
+		default:
+			token.WriteByte(c)
+		}
+	}
+	endToken()
+	if depth > 0 {
+		return nil, &ZoneParseError{Line: line, Message: "missing )"}
+	}
+	if len(entry.tokens) > 0 {
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// zoneParser holds the state that carries between the entries of a zone file.
+type zoneParser struct {
+	// apex is the domain of the zone, set by the first $ORIGIN, and origin is the domain of the latest $ORIGIN that
+	// relative names are completed with.
+	apex       string
+	origin     string
+	defaultTTL int
+	owner      string
+	hasOwner   bool
+}
+
+// ParseZoneFile will read the records of an RFC 1035 master file, also known as a BIND zone file. The $ORIGIN and $TTL
+// directives are supported. The first $ORIGIN is the domain of the zone, record names are made relative to it with the
+// domain itself converted to the empty name that CreateDNSRecord expects, so records after a later $ORIGIN such as
+// sub.example.com. are named under sub. Hostnames in record values are made fully qualified. SOA and NS records are managed by ZEIT
+// so they are skipped. A *ZoneParseError is returned with the line of the first problem found.
+func ParseZoneFile(r io.Reader) ([]Record, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := tokenizeZone(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p := &zoneParser{}
+	records := make([]Record, 0, len(entries))
+	for _, entry := range entries {
+		record, err := p.entry(entry)
+		if err != nil {
+			return nil, &ZoneParseError{Line: entry.line, Message: err.Error()}
+		}
+		if record != nil {
+			records = append(records, *record)
+		}
+	}
+	return records, nil
+}
+
+// entry parses a single entry, directives and skipped records return a nil record.
+func (p *zoneParser) entry(entry zoneEntry) (*Record, error) {
+	tokens := entry.tokens
+	if strings.HasPrefix(tokens[0], "$") {
+		return nil, p.directive(tokens)
+	}
+
+	if entry.indented {
+		if !p.hasOwner {
+			return nil, fmt.Errorf("record has no name and there is no previous record")
+		}
+	} else {
+		owner, err := p.relativeName(tokens[0])
+		if err != nil {
+			return nil, err
+		}
+		p.owner, p.hasOwner = owner, true
+		tokens = tokens[1:]
+	}
+
+	// the ttl and class are optional and can be in either order
+	ttl := p.defaultTTL
+	for i := 0; i < 2 && len(tokens) > 0; i++ {
+		if isDigits(tokens[0][:1]) {
+			var err error
+			if ttl, err = parseZoneTTL(tokens[0]); err != nil {
+				return nil, err
+			}
+		} else if class := strings.ToUpper(tokens[0]); class == "IN" || class == "CH" || class == "HS" {
+			if class != "IN" {
+				return nil, fmt.Errorf("unsupported class %s", class)
+			}
+		} else {
+			break
+		}
+		tokens = tokens[1:]
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("record has no type")
+	}
+
+	record := &Record{Name: p.owner, Type: strings.ToUpper(tokens[0]), TTL: ttl}
+	data := tokens[1:]
+	expect := func(n int, format string) error {
+		if len(data) != n {
+			return fmt.Errorf("%s record should have the form %s", record.Type, format)
+		}
+		return nil
+	}
+
+	var err error
+	switch record.Type {
+	case "SOA", "NS":
+		return nil, nil
+	case RecordTypeA, RecordTypeAAAA:
+		if err = expect(1, "<address>"); err == nil {
+			record.Value = data[0]
+		}
+	case RecordTypeCNAME, RecordTypeALIAS:
+		if err = expect(1, "<hostname>"); err == nil {
+			record.Value, err = p.hostname(data[0])
+		}
+	case RecordTypeMX:
+		if err = expect(2, "<priority> <hostname>"); err == nil {
+			if _, err = parseUint16("MX priority", data[0]); err == nil {
+				record.MxPriority = data[0]
+				record.Value, err = p.hostname(data[1])
+			}
+		}
+	case RecordTypeSRV:
+		if err = expect(4, "<priority> <weight> <port> <target>"); err == nil {
+			var target string
+			if target, err = p.hostname(data[3]); err == nil {
+				var srv SRVValue
+				srv, err = ParseSRVValue(strings.Join([]string{data[0], data[1], data[2], target}, " "))
+				record.SrvPriority = strconv.Itoa(srv.Priority)
+				record.Value = fmt.Sprintf("%d %d %s", srv.Weight, srv.Port, srv.Target)
+			}
+		}
+	case RecordTypeCAA:
+		if err = expect(3, `<flags> <tag> "<value>"`); err == nil {
+			var caa CAAValue
+			if caa, err = ParseCAAValue(fmt.Sprintf("%s %s %s", data[0], data[1], zoneUnquote(data[2]))); err == nil {
+				record.Value = caa.String()
+			}
+		}
+	case RecordTypeTXT:
+		if len(data) == 0 {
+			return nil, fmt.Errorf("TXT record has no text")
+		}
+		record.Value = txtFromZone(data)
+	default:
+		return nil, fmt.Errorf("unsupported record type %s", record.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// txtFromZone joins the strings of a TXT record into a single unquoted value, the API stores long text as is.
+func txtFromZone(data []string) string {
+	chunks := make([]string, len(data))
+	for i, token := range data {
+		chunks[i] = zoneUnquote(token)
+	}
+	return strings.Join(chunks, "")
+}
+
+// directive handles the $ORIGIN and $TTL directives.
+func (p *zoneParser) directive(tokens []string) error {
+	switch strings.ToUpper(tokens[0]) {
+	case "$ORIGIN":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$ORIGIN should have the form $ORIGIN <domain>")
+		}
+		origin, err := p.hostname(tokens[1])
+		if err != nil {
+			return err
+		}
+		p.origin = strings.ToLower(strings.TrimSuffix(origin, "."))
+		if p.apex == "" {
+			p.apex = p.origin
+		}
+	case "$TTL":
+		if len(tokens) != 2 {
+			return fmt.Errorf("$TTL should have the form $TTL <ttl>")
+		}
+		ttl, err := parseZoneTTL(tokens[1])
+		if err != nil {
+			return err
+		}
+		p.defaultTTL = ttl
+	default:
+		return fmt.Errorf("unsupported directive %s", tokens[0])
+	}
+	return nil
+}
+
+// relativeName converts the owner of a record to a name relative to the apex of the zone. Relative owners are completed
+// with the current origin first, so they are placed under it when it differs from the apex.
+func (p *zoneParser) relativeName(name string) (string, error) {
+	if p.origin == "" {
+		switch {
+		case name == "@":
+			return "", nil
+		case strings.HasSuffix(name, "."):
+			return "", fmt.Errorf("name %s is fully qualified but there is no $ORIGIN", name)
+		}
+		return name, nil
+	}
+	fqdn, err := p.hostname(name)
+	if err != nil {
+		return "", err
+	}
+	fqdn = strings.TrimSuffix(fqdn, ".")
+	lower := strings.ToLower(fqdn)
+	if lower == p.apex {
+		return "", nil
+	}
+	if !strings.HasSuffix(lower, "."+p.apex) {
+		return "", fmt.Errorf("name %s is outside of the zone %s", name, p.apex)
+	}
+	return fqdn[:len(fqdn)-len(p.apex)-1], nil
+}
+
+// hostname converts a hostname in the data of a record to a fully qualified name.
+func (p *zoneParser) hostname(name string) (string, error) {
+	switch {
+	case strings.HasSuffix(name, "."):
+		return name, nil
+	case p.origin == "":
+		if name == "@" {
+			return "", fmt.Errorf("@ can't be used before $ORIGIN")
+		}
+		return name, nil
+	case name == "@":
+		return p.origin + ".", nil
+	default:
+		return name + "." + p.origin + ".", nil
+	}
+}
+
+// parseZoneTTL parses a TTL in seconds, the BIND units s, m, h, d and w are also supported, such as 1h30m.
+func parseZoneTTL(value string) (int, error) {
+	units := map[byte]int{'s': 1, 'm': 60, 'h': 60 * 60, 'd': 24 * 60 * 60, 'w': 7 * 24 * 60 * 60}
+	if isDigits(value) {
+		ttl, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || ttl > maxZoneTTL {
+			return 0, fmt.Errorf("invalid TTL %s", value)
+		}
+		return int(ttl), nil
+	}
+	total, number := 0, ""
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c >= '0' && c <= '9' {
+			number += string(c)
+			continue
+		}
+		unit, ok := units[byte(unicode.ToLower(rune(c)))]
+		if !ok || number == "" {
+			return 0, fmt.Errorf("invalid TTL %s", value)
+		}
+		n, _ := strconv.Atoi(number)
+		total += n * unit
+		number = ""
+	}
+	if number != "" || total > maxZoneTTL {
+		return 0, fmt.Errorf("invalid TTL %s", value)
+	}
+	return total, nil
+}
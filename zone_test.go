@@ -0,0 +1,142 @@
+package zeit
+
+import (
+	"errors"
+	"github.com/kochie/zeit-api-go/zeittest"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+const testZone = `
+$ORIGIN example.com.
+$TTL 1h
+; the SOA and NS records are managed by ZEIT
+@	IN	SOA	ns1.example.com. hostmaster.example.com. (
+		2019051101 ; serial
+		7200       ; refresh
+		3600       ; retry
+		1209600    ; expire
+		3600 )     ; minimum
+@		IN	NS	a.zeit-world.net.
+@		IN	A	1.1.1.1
+		IN	A	1.0.0.1
+www	300	IN	CNAME	@
+blog.example.com.	IN	ALIAS	hosting.example.net.
+@	IN	MX	10 mail
+_sip._tcp	IN	SRV	10 20 5060 sip.example.com.
+@	IN	CAA	0 issue "letsencrypt.org"
+@	IN	TXT	"v=spf1 include:_spf.google.com ~all" ; spf
+_dmarc	IN	TXT	"v=DMARC1; " "p=none"
+`
+
+func TestParseZoneFile(t *testing.T) {
+	a := assert.New(t)
+
+	records, err := ParseZoneFile(strings.NewReader(testZone))
+	a.Nil(err, "Error should be nil")
+	a.Equal([]Record{
+		{Name: "", Type: RecordTypeA, Value: "1.1.1.1", TTL: 3600},
+		{Name: "", Type: RecordTypeA, Value: "1.0.0.1", TTL: 3600},
+		{Name: "www", Type: RecordTypeCNAME, Value: "example.com.", TTL: 300},
+		{Name: "blog", Type: RecordTypeALIAS, Value: "hosting.example.net.", TTL: 3600},
+		{Name: "", Type: RecordTypeMX, Value: "mail.example.com.", MxPriority: "10", TTL: 3600},
+		{Name: "_sip._tcp", Type: RecordTypeSRV, Value: "20 5060 sip.example.com.", SrvPriority: "10", TTL: 3600},
+		{Name: "", Type: RecordTypeCAA, Value: `0 issue "letsencrypt.org"`, TTL: 3600},
+		{Name: "", Type: RecordTypeTXT, Value: "v=spf1 include:_spf.google.com ~all", TTL: 3600},
+		{Name: "_dmarc", Type: RecordTypeTXT, Value: "v=DMARC1; p=none", TTL: 3600},
+	}, records)
+	a.Nil(ValidateRecords(records), "parsed records should be valid")
+
+	// names after a later $ORIGIN are relative to the first, the domain of the zone
+	records, err = ParseZoneFile(strings.NewReader(`$ORIGIN example.com.
+www     A 1.1.1.1
+$ORIGIN sub.example.com.
+www     A 2.2.2.2
+@       A 3.3.3.3
+x.sub.example.com. A 4.4.4.4
+$ORIGIN deep
+Host    A 5.5.5.5
+`))
+	a.Nil(err, "Error should be nil")
+	var names []string
+	for _, record := range records {
+		names = append(names, record.Name)
+	}
+	a.Equal([]string{"www", "www.sub", "sub", "x.sub", "Host.deep.sub"}, names)
+}
+
+func TestParseZoneFile_Errors(t *testing.T) {
+	zones := map[string]string{
+		"outside origin":      "$ORIGIN example.com.\nwww.example.net. IN A 1.1.1.1\n",
+		"origin outside zone": "$ORIGIN example.com.\n$ORIGIN example.net.\nwww IN A 1.1.1.1\n",
+		"no origin":           "www.example.com. IN A 1.1.1.1\n",
+		"unsupported type":    "\n\nwww IN HINFO cpu os\n",
+		"no previous owner":   "  IN A 1.1.1.1\n",
+		"missing paren":       "www IN TXT ( \"hello\"\n",
+		"unterminated quote":  "www IN TXT \"hello\n",
+		"bad ttl":             "$TTL 1x\n",
+		"bad mx":              "@ IN MX mail.example.com.\n",
+		"include":             "$INCLUDE other.zone\n",
+	}
+	lines := map[string]int{"unsupported type": 3}
+
+	for name, zone := range zones {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseZoneFile(strings.NewReader(zone))
+			var parseError *ZoneParseError
+			assert.True(t, errors.As(err, &parseError), "zone should be invalid")
+			if line, ok := lines[name]; ok {
+				assert.Equal(t, line, parseError.Line, "error should have the line number")
+			}
+		})
+	}
+}
+
+func TestWriteZone_RoundTrip(t *testing.T) {
+	a := assert.New(t)
+
+	records := []Record{
+		{Name: "", Type: RecordTypeA, Value: "1.1.1.1", TTL: 60},
+		{Name: "www", Type: RecordTypeCNAME, Value: "example.com"},
+		*NewMXRecord("", 10, "aspmx.l.google.com"),
+		*NewSRVRecord("_sip._tcp", 10, 20, 5060, "sip.example.com"),
+		*NewCAARecord("", 0, "issue", "letsencrypt.org"),
+		*NewTXTRecord("", `say "hello"`),
+		*NewTXTRecord("dkim", strings.Repeat("k", 300)),
+	}
+
+	var zone strings.Builder
+	a.Nil(WriteZone(&zone, "example.com", records))
+	a.Contains(zone.String(), "$ORIGIN example.com.\n")
+	a.Contains(zone.String(), "www\t\tIN\tCNAME\texample.com.\n", "hostnames should be fully qualified")
+
+	parsed, err := ParseZoneFile(strings.NewReader(zone.String()))
+	a.Nil(err, "Error should be nil")
+	a.Len(parsed, len(records))
+	for i := range records {
+		a.Equal(records[i].Name, parsed[i].Name)
+		a.Equal(records[i].Type, parsed[i].Type)
+		a.Equal(zoneData(&records[i]), zoneData(&parsed[i]), "values should round trip")
+	}
+	a.Equal(60, parsed[0].TTL)
+	a.Equal(`say "hello"`, parsed[5].Value)
+	a.Equal(strings.Repeat("k", 300), parsed[6].Value, "long text should be read back as a single string")
+}
+
+func TestClient_ExportZone(t *testing.T) {
+	a := assert.New(t)
+	server := zeittest.NewServer()
+	defer server.Close()
+	server.AddDomain("", "example.com")
+	_, err := server.AddRecord("", "example.com", zeittest.Record{Name: "www", Type: RecordTypeA, Value: "1.1.1.1"})
+	a.Nil(err)
+	client := NewClient(server.Token, WithBaseURL(server.URL))
+
+	zone, err := client.ExportZone("example.com")
+	a.Nil(err, "Error should be nil")
+	data, err := ioutil.ReadAll(zone)
+	a.Nil(err)
+	a.Equal("; example.com exported from ZEIT\n$ORIGIN example.com.\nwww\t\tIN\tA\t1.1.1.1\n", string(data))
+}
@@ -0,0 +1,457 @@
+// Package zoneconfig reads and writes DNS records kept in configuration files, so changes to a zone can be reviewed
+// like any other change to code. Files are YAML, and as JSON is a subset of YAML they can also be JSON. A file holds
+// either a single domain:
+//
+//	domain: example.com
+//	records:
+//	  # web frontends
+//	  - name: www
+//	    type: A
+//	    ttl: 300
+//	    values: [203.0.113.10, 203.0.113.11]
+//	  - name: "@"
+//	    type: MX
+//	    mx: {priority: 10, host: mail.example.com}
+//
+// or many domains under a domains key. Every record has a name and type and exactly one of value, values, mx, srv or
+// caa. A comment above a record, or its comment field, is kept with the record.
+package zoneconfig
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kochie/zeit-api-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the contents of a configuration file.
+type Config struct {
+	Zones []Zone
+}
+
+// Zone is the desired records of a domain.
+type Zone struct {
+	Domain  string
+	Entries []Entry
+
+	line int
+}
+
+// Entry is a record in a configuration file. The value is set with exactly one of Value, Values, MX, SRV or CAA,
+// Values creates a record for each value, such as round robin A records.
+type Entry struct {
+	Name    string   `yaml:"name"`
+	Type    string   `yaml:"type"`
+	TTL     int      `yaml:"ttl"`
+	Value   string   `yaml:"value"`
+	Values  []string `yaml:"values"`
+	MX      *MX      `yaml:"mx"`
+	SRV     *SRV     `yaml:"srv"`
+	CAA     *CAA     `yaml:"caa"`
+	Comment string   `yaml:"comment"`
+
+	line int
+}
+
+// MX is the value of an MX record.
+type MX struct {
+	Priority int    `yaml:"priority"`
+	Host     string `yaml:"host"`
+}
+
+// SRV is the value of an SRV record.
+type SRV struct {
+	Priority int    `yaml:"priority"`
+	Weight   int    `yaml:"weight"`
+	Port     int    `yaml:"port"`
+	Target   string `yaml:"target"`
+}
+
+// CAA is the value of a CAA record.
+type CAA struct {
+	Flags int    `yaml:"flags"`
+	Tag   string `yaml:"tag"`
+	Value string `yaml:"value"`
+}
+
+var (
+	fileKeys   = []string{"domains"}
+	zoneKeys   = []string{"domain", "records"}
+	entryKeys  = []string{"name", "type", "ttl", "value", "values", "mx", "srv", "caa", "comment"}
+	mxKeys     = []string{"priority", "host"}
+	srvKeys    = []string{"priority", "weight", "port", "target"}
+	caaKeys    = []string{"flags", "tag", "value"}
+	valueKeys  = map[string][]string{"mx": mxKeys, "srv": srvKeys, "caa": caaKeys}
+	recordType = map[string]bool{
+		zeit.RecordTypeA:     true,
+		zeit.RecordTypeAAAA:  true,
+		zeit.RecordTypeALIAS: true,
+		zeit.RecordTypeCAA:   true,
+		zeit.RecordTypeCNAME: true,
+		zeit.RecordTypeMX:    true,
+		zeit.RecordTypeSRV:   true,
+		zeit.RecordTypeTXT:   true,
+	}
+)
+
+// LineError is a problem found at a line of a configuration file.
+type LineError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e LineError) Error() string {
+	if e.File != "" {
+		return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+}
+
+// ValidationError lists every problem found in a configuration file.
+type ValidationError struct {
+	Errors []LineError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	if len(messages) == 1 {
+		return "invalid zone config: " + messages[0]
+	}
+	return fmt.Sprintf("%d problems found in zone config: %s", len(messages), strings.Join(messages, "; "))
+}
+
+// checker collects the problems found in a configuration file.
+type checker struct {
+	errors []LineError
+}
+
+func (c *checker) add(line int, format string, args ...interface{}) {
+	c.errors = append(c.errors, LineError{Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) err(file string) error {
+	if len(c.errors) == 0 {
+		return nil
+	}
+	for i := range c.errors {
+		c.errors[i].File = file
+	}
+	return &ValidationError{Errors: c.errors}
+}
+
+// LoadFile will read and validate the configuration file at path. Errors name the file and line of each problem.
+func LoadFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return load(f, path)
+}
+
+// Load will read and validate a configuration file, every problem found is returned in a *ValidationError.
+func Load(r io.Reader) (*Config, error) {
+	return load(r, "")
+}
+
+func load(r io.Reader, file string) (*Config, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		if err == io.EOF {
+			return nil, &ValidationError{Errors: []LineError{{File: file, Line: 1, Message: "file is empty"}}}
+		}
+		if file != "" {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		return nil, err
+	}
+
+	c := &checker{}
+	config := &Config{}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		c.add(root.Line, "expected a mapping with a domain or domains key")
+		return nil, c.err(file)
+	}
+	if domains := lookup(root, "domains"); domains != nil {
+		c.keys(root, fileKeys)
+		if domains.Kind != yaml.SequenceNode {
+			c.add(domains.Line, "domains should be a list")
+		}
+		for _, node := range domains.Content {
+			config.Zones = append(config.Zones, c.zone(node))
+		}
+	} else {
+		config.Zones = append(config.Zones, c.zone(root))
+	}
+	if len(c.errors) == 0 {
+		config.validate(c)
+	}
+	if err := c.err(file); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// zone reads a zone from its node.
+func (c *checker) zone(node *yaml.Node) Zone {
+	zone := Zone{line: node.Line}
+	if node.Kind != yaml.MappingNode {
+		c.add(node.Line, "expected a mapping with domain and records keys")
+		return zone
+	}
+	c.keys(node, zoneKeys)
+	if domain := lookup(node, "domain"); domain != nil {
+		zone.Domain = domain.Value
+	}
+	records := lookup(node, "records")
+	if records == nil {
+		return zone
+	}
+	if records.Kind != yaml.SequenceNode {
+		c.add(records.Line, "records should be a list")
+		return zone
+	}
+	for _, item := range records.Content {
+		zone.Entries = append(zone.Entries, c.entry(item))
+	}
+	return zone
+}
+
+// entry reads a record from its node, a comment above the record is used when it doesn't have a comment field.
+func (c *checker) entry(node *yaml.Node) Entry {
+	entry := Entry{line: node.Line}
+	if node.Kind != yaml.MappingNode {
+		c.add(node.Line, "expected a record")
+		return entry
+	}
+	c.keys(node, entryKeys)
+	for _, key := range []string{"mx", "srv", "caa"} {
+		if value := lookup(node, key); value != nil && value.Kind == yaml.MappingNode {
+			c.keys(value, valueKeys[key])
+		}
+	}
+	if err := node.Decode(&entry); err != nil {
+		if typeError, ok := err.(*yaml.TypeError); ok {
+			for _, message := range typeError.Errors {
+				line, message := splitLine(message, node.Line)
+				c.add(line, "%s", message)
+			}
+		} else {
+			c.add(node.Line, "%s", err)
+		}
+	}
+	entry.line = node.Line
+	if entry.Comment == "" {
+		entry.Comment = parseComment(node.HeadComment)
+	}
+	return entry
+}
+
+// keys adds an error for each key of the mapping that isn't allowed, or is repeated.
+func (c *checker) keys(node *yaml.Node, allowed []string) {
+	seen := make(map[string]bool)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !contains(allowed, key.Value) {
+			c.add(key.Line, "unknown field %q, expected one of %s", key.Value, strings.Join(allowed, ", "))
+		} else if seen[key.Value] {
+			c.add(key.Line, "field %q is repeated", key.Value)
+		}
+		seen[key.Value] = true
+	}
+}
+
+// Validate checks the configuration the same way Load does, use it after changing a Config.
+func (c *Config) Validate() error {
+	v := &checker{}
+	c.validate(v)
+	return v.err("")
+}
+
+// validate checks every zone has a domain and its records are valid, see zeit.Record.Validate.
+func (c *Config) validate(v *checker) {
+	domains := make(map[string]bool)
+	for i := range c.Zones {
+		zone := &c.Zones[i]
+		switch {
+		case zone.Domain == "":
+			v.add(zone.line, "domain is missing")
+		case domains[strings.ToLower(zone.Domain)]:
+			v.add(zone.line, "domain %s is repeated", zone.Domain)
+		}
+		domains[strings.ToLower(zone.Domain)] = true
+		zone.validate(v)
+	}
+}
+
+func (z *Zone) validate(v *checker) {
+	counts := make(map[string]int)
+	for i := range z.Entries {
+		entry := &z.Entries[i]
+		entry.validate(v)
+		counts[entry.name()] += len(entry.records())
+	}
+	for i := range z.Entries {
+		entry := &z.Entries[i]
+		if entry.Type == zeit.RecordTypeCNAME && counts[entry.name()] > 1 {
+			v.add(entry.line, "a CNAME can't share its name with any other record, found %d records",
+				counts[entry.name()])
+		}
+	}
+}
+
+func (e *Entry) validate(v *checker) {
+	if e.Type == "" {
+		v.add(e.line, "type is missing")
+		return
+	}
+	if !recordType[e.Type] {
+		v.add(e.line, "unknown record type %q", e.Type)
+		return
+	}
+
+	var set []string
+	if e.Value != "" {
+		set = append(set, "value")
+	}
+	if e.Values != nil {
+		set = append(set, "values")
+	}
+	if e.MX != nil {
+		set = append(set, "mx")
+	}
+	if e.SRV != nil {
+		set = append(set, "srv")
+	}
+	if e.CAA != nil {
+		set = append(set, "caa")
+	}
+	switch {
+	case len(set) == 0:
+		v.add(e.line, "one of value, values, mx, srv or caa is required")
+		return
+	case len(set) > 1:
+		v.add(e.line, "only one of %s can be set", strings.Join(set, " or "))
+		return
+	case e.MX != nil && e.Type != zeit.RecordTypeMX,
+		e.SRV != nil && e.Type != zeit.RecordTypeSRV,
+		e.CAA != nil && e.Type != zeit.RecordTypeCAA:
+		v.add(e.line, "%s can't be used with a %s record", set[0], e.Type)
+		return
+	case e.Values != nil && len(e.Values) == 0:
+		v.add(e.line, "values is empty")
+		return
+	case len(e.Values) > 1 && (e.Type == zeit.RecordTypeCNAME || e.Type == zeit.RecordTypeALIAS):
+		v.add(e.line, "a %s record can only have one value", e.Type)
+		return
+	}
+	if e.TTL < 0 {
+		v.add(e.line, "ttl can't be negative")
+	}
+
+	for _, record := range e.records() {
+		if err := record.Validate(); err != nil {
+			for _, recordError := range err.(*zeit.ValidationError).Errors {
+				v.add(e.line, "%s", recordError.Message)
+			}
+		}
+	}
+}
+
+// Records returns the records of the zone, ready to be passed to CreateDNSRecord or dnsplan.Compute.
+func (z *Zone) Records() []zeit.Record {
+	var records []zeit.Record
+	for i := range z.Entries {
+		records = append(records, z.Entries[i].records()...)
+	}
+	return records
+}
+
+// Zone returns the zone of the domain.
+func (c *Config) Zone(domain string) (*Zone, bool) {
+	for i := range c.Zones {
+		if strings.EqualFold(c.Zones[i].Domain, strings.TrimSuffix(domain, ".")) {
+			return &c.Zones[i], true
+		}
+	}
+	return nil, false
+}
+
+// name returns the name of the entry as used by the API, where the apex of the domain is an empty name.
+func (e *Entry) name() string {
+	if e.Name == "@" {
+		return ""
+	}
+	return e.Name
+}
+
+// records returns a record for each value of the entry.
+func (e *Entry) records() []zeit.Record {
+	var records []*zeit.Record
+	switch {
+	case e.MX != nil:
+		records = append(records, zeit.NewMXRecord(e.name(), e.MX.Priority, e.MX.Host))
+	case e.SRV != nil:
+		records = append(records, zeit.NewSRVRecord(e.name(), e.SRV.Priority, e.SRV.Weight, e.SRV.Port, e.SRV.Target))
+	case e.CAA != nil:
+		records = append(records, zeit.NewCAARecord(e.name(), e.CAA.Flags, e.CAA.Tag, e.CAA.Value))
+	case e.Values != nil:
+		for _, value := range e.Values {
+			records = append(records, &zeit.Record{Name: e.name(), Type: e.Type, Value: value})
+		}
+	default:
+		records = append(records, &zeit.Record{Name: e.name(), Type: e.Type, Value: e.Value})
+	}
+
+	result := make([]zeit.Record, len(records))
+	for i, record := range records {
+		record.TTL = e.TTL
+		result[i] = *record
+	}
+	return result
+}
+
+// lookup returns the value of the key in a mapping node.
+func lookup(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// splitLine splits the line number from a message of a yaml.TypeError, "line 3: cannot unmarshal ...".
+func splitLine(message string, line int) (int, string) {
+	var n int
+	if _, err := fmt.Sscanf(message, "line %d:", &n); err == nil {
+		return n, strings.TrimSpace(message[strings.Index(message, ":")+1:])
+	}
+	return line, message
+}
+
+// parseComment returns the text of a YAML comment without the leading #.
+func parseComment(comment string) string {
+	lines := strings.Split(comment, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "#"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
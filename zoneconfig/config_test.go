@@ -0,0 +1,203 @@
+package zoneconfig
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kochie/zeit-api-go"
+	"github.com/stretchr/testify/assert"
+)
+
+const single = `domain: example.com
+records:
+  # web frontends
+  - name: www
+    type: A
+    ttl: 300
+    values: [203.0.113.10, 203.0.113.11]
+  - name: "@"
+    type: MX
+    mx: {priority: 10, host: mail.example.com}
+  - name: _sip._tcp
+    type: SRV
+    srv: {priority: 10, weight: 5, port: 5060, target: sip.example.com}
+  - name: "@"
+    type: CAA
+    caa: {flags: 0, tag: issue, value: letsencrypt.org}
+  - name: api
+    type: CNAME
+    value: api.example.net
+    comment: hosted elsewhere
+`
+
+func TestLoad(t *testing.T) {
+	a := assert.New(t)
+
+	config, err := Load(strings.NewReader(single))
+	a.Nil(err, "Error should be nil")
+	a.Len(config.Zones, 1)
+
+	zone, ok := config.Zone("example.com.")
+	a.True(ok, "zone should be found")
+	a.Equal("web frontends", zone.Entries[0].Comment, "comment above the record should be kept")
+	a.Equal("hosted elsewhere", zone.Entries[4].Comment)
+	a.Equal([]zeit.Record{
+		{Name: "www", Type: zeit.RecordTypeA, Value: "203.0.113.10", TTL: 300},
+		{Name: "www", Type: zeit.RecordTypeA, Value: "203.0.113.11", TTL: 300},
+		*zeit.NewMXRecord("", 10, "mail.example.com"),
+		*zeit.NewSRVRecord("_sip._tcp", 10, 5, 5060, "sip.example.com"),
+		*zeit.NewCAARecord("", 0, "issue", "letsencrypt.org"),
+		{Name: "api", Type: zeit.RecordTypeCNAME, Value: "api.example.net"},
+	}, zone.Records())
+}
+
+func TestLoad_ManyDomains(t *testing.T) {
+	a := assert.New(t)
+
+	config, err := Load(strings.NewReader(`{
+  "domains": [
+    {"domain": "example.com", "records": [{"name": "www", "type": "A", "value": "203.0.113.10"}]},
+    {"domain": "example.net", "records": [{"name": "", "type": "TXT", "value": "hello"}]}
+  ]
+}`))
+	a.Nil(err, "JSON should be accepted")
+	a.Len(config.Zones, 2)
+	zone, ok := config.Zone("example.net")
+	a.True(ok, "zone should be found")
+	a.Equal([]zeit.Record{{Type: zeit.RecordTypeTXT, Value: "hello"}}, zone.Records())
+}
+
+func TestLoad_Errors(t *testing.T) {
+	a := assert.New(t)
+
+	_, err := Load(strings.NewReader(`domain: example.com
+records:
+  - name: www
+    type: A
+    value: 203.0.113.10
+    valeu: oops
+  - name: www
+    type: A
+  - name: mail
+    type: MX
+    value: mail.example.com
+    mx: {priority: 10, host: mail.example.com}
+  - name: bad
+    type: A
+    value: not an address
+  - name: ttl
+    type: A
+    ttl: soon
+    value: 203.0.113.10
+  - name: "@"
+    type: FOO
+    value: x
+`))
+	var validationError *ValidationError
+	a.True(errors.As(err, &validationError), "should return a ValidationError")
+	a.Equal([]LineError{
+		{Line: 6, Message: `unknown field "valeu", expected one of name, type, ttl, value, values, mx, srv, caa, comment`},
+		{Line: 18, Message: "cannot unmarshal !!str `soon` into int"},
+	}, validationError.Errors, "structural problems should be found first")
+
+	_, err = Load(strings.NewReader(`domain: example.com
+records:
+  - name: www
+    type: A
+  - name: mail
+    type: MX
+    value: mail.example.com
+    mx: {priority: 10, host: mail.example.com}
+  - name: bad
+    type: A
+    value: not an address
+  - name: "@"
+    type: FOO
+    value: x
+  - name: www
+    type: CNAME
+    value: example.net
+`))
+	a.True(errors.As(err, &validationError), "should return a ValidationError")
+	a.Len(validationError.Errors, 5)
+	a.Equal(3, validationError.Errors[0].Line)
+	a.Equal("only one of value or mx can be set", validationError.Errors[1].Message)
+	a.Equal(5, validationError.Errors[1].Line)
+	a.Equal(9, validationError.Errors[2].Line)
+	a.Equal(`unknown record type "FOO"`, validationError.Errors[3].Message)
+	a.Equal(15, validationError.Errors[4].Line)
+	a.Contains(validationError.Errors[4].Message, "CNAME")
+
+	_, err = Load(strings.NewReader(``))
+	a.True(errors.As(err, &validationError), "empty files should be rejected")
+}
+
+func TestLoadFile(t *testing.T) {
+	a := assert.New(t)
+	dir, err := ioutil.TempDir("", "zoneconfig")
+	a.Nil(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "example.com.yaml")
+	a.Nil(ioutil.WriteFile(path, []byte("domain: example.com\nrecords:\n  - name: www\n    type: A\n"), 0644))
+
+	_, err = LoadFile(path)
+	a.EqualError(err, "invalid zone config: "+path+":3: one of value, values, mx, srv or caa is required")
+}
+
+func TestFormat(t *testing.T) {
+	a := assert.New(t)
+
+	config, err := Load(strings.NewReader(single))
+	a.Nil(err)
+	buf := &bytes.Buffer{}
+	a.Nil(Format(buf, config))
+	a.Equal(`domain: example.com
+records:
+  - name: '@'
+    type: CAA
+    caa: {flags: 0, tag: issue, value: letsencrypt.org}
+  - name: '@'
+    type: MX
+    mx: {priority: 10, host: mail.example.com}
+  - name: _sip._tcp
+    type: SRV
+    srv: {priority: 10, weight: 5, port: 5060, target: sip.example.com}
+  # hosted elsewhere
+  - name: api
+    type: CNAME
+    value: api.example.net
+  # web frontends
+  - name: www
+    type: A
+    ttl: 300
+    values: [203.0.113.10, 203.0.113.11]
+`, buf.String())
+
+	formatted, err := Load(bytes.NewReader(buf.Bytes()))
+	a.Nil(err, "formatted config should load")
+	again := &bytes.Buffer{}
+	a.Nil(Format(again, formatted))
+	a.Equal(buf.String(), again.String(), "formatting should be stable")
+}
+
+func TestFromRecords(t *testing.T) {
+	a := assert.New(t)
+
+	records := []zeit.Record{
+		{Name: "www", Type: zeit.RecordTypeA, Value: "203.0.113.10"},
+		{Name: "www", Type: zeit.RecordTypeA, Value: "203.0.113.11"},
+		*zeit.NewMXRecord("", 10, "mail.example.com"),
+		{Name: "", Type: zeit.RecordTypeTXT, Value: "hello"},
+	}
+	zone := FromRecords("example.com", records)
+	a.Len(zone.Entries, 3, "A records should be grouped")
+	a.Equal(&MX{Priority: 10, Host: "mail.example.com"}, zone.Entries[1].MX)
+	a.Equal(records, zone.Records(), "records should round trip")
+	a.Nil((&Config{Zones: []Zone{zone}}).Validate())
+}
@@ -0,0 +1,168 @@
+package zoneconfig
+
+import (
+	"bytes"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kochie/zeit-api-go"
+	"gopkg.in/yaml.v3"
+)
+
+// Format will write the configuration in its canonical form. Zones are sorted by domain and records by name and type,
+// the apex is written as "@", keys are written in a fixed order and comments are written above their record. A single
+// zone is written without the domains key. Formatting a file that is already canonical doesn't change it, so the
+// output can be checked in CI.
+func Format(w io.Writer, config *Config) error {
+	zones := make([]Zone, len(config.Zones))
+	copy(zones, config.Zones)
+	sort.SliceStable(zones, func(i, j int) bool {
+		return strings.ToLower(zones[i].Domain) < strings.ToLower(zones[j].Domain)
+	})
+
+	var root *yaml.Node
+	if len(zones) == 1 {
+		root = zoneNode(&zones[0])
+	} else {
+		domains := &yaml.Node{Kind: yaml.SequenceNode}
+		for i := range zones {
+			domains.Content = append(domains.Content, zoneNode(&zones[i]))
+		}
+		root = mapping("domains", domains)
+	}
+
+	buf := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func zoneNode(zone *Zone) *yaml.Node {
+	entries := make([]Entry, len(zone.Entries))
+	copy(entries, zone.Entries)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].name() != entries[j].name() {
+			return entries[i].name() < entries[j].name()
+		}
+		return entries[i].Type < entries[j].Type
+	})
+
+	records := &yaml.Node{Kind: yaml.SequenceNode}
+	for i := range entries {
+		records.Content = append(records.Content, entryNode(&entries[i]))
+	}
+	return mapping("domain", scalar(zone.Domain), "records", records)
+}
+
+func entryNode(entry *Entry) *yaml.Node {
+	name := entry.name()
+	if name == "" {
+		name = "@"
+	}
+	node := mapping("name", scalar(name), "type", scalar(entry.Type))
+	if entry.TTL != 0 {
+		node.Content = append(node.Content, scalar("ttl"), number(entry.TTL))
+	}
+
+	switch {
+	case entry.MX != nil:
+		node.Content = append(node.Content, scalar("mx"), flow(mapping(
+			"priority", number(entry.MX.Priority),
+			"host", scalar(entry.MX.Host),
+		)))
+	case entry.SRV != nil:
+		node.Content = append(node.Content, scalar("srv"), flow(mapping(
+			"priority", number(entry.SRV.Priority),
+			"weight", number(entry.SRV.Weight),
+			"port", number(entry.SRV.Port),
+			"target", scalar(entry.SRV.Target),
+		)))
+	case entry.CAA != nil:
+		node.Content = append(node.Content, scalar("caa"), flow(mapping(
+			"flags", number(entry.CAA.Flags),
+			"tag", scalar(entry.CAA.Tag),
+			"value", scalar(entry.CAA.Value),
+		)))
+	case len(entry.Values) == 1:
+		node.Content = append(node.Content, scalar("value"), scalar(entry.Values[0]))
+	case entry.Values != nil:
+		values := flow(&yaml.Node{Kind: yaml.SequenceNode})
+		for _, value := range entry.Values {
+			values.Content = append(values.Content, scalar(value))
+		}
+		node.Content = append(node.Content, scalar("values"), values)
+	default:
+		node.Content = append(node.Content, scalar("value"), scalar(entry.Value))
+	}
+
+	if entry.Comment != "" {
+		node.HeadComment = "# " + strings.Replace(entry.Comment, "\n", "\n# ", -1)
+	}
+	return node
+}
+
+// mapping returns a mapping node of the keys and values.
+func mapping(pairs ...interface{}) *yaml.Node {
+	node := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i < len(pairs); i += 2 {
+		node.Content = append(node.Content, scalar(pairs[i].(string)), pairs[i+1].(*yaml.Node))
+	}
+	return node
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func number(value int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)}
+}
+
+func flow(node *yaml.Node) *yaml.Node {
+	node.Style = yaml.FlowStyle
+	return node
+}
+
+// FromRecords will create the zone of a domain from its records, such as those returned by ListDNSRecords, as a
+// starting point for a configuration file. A and AAAA records with the same name and TTL are grouped into one entry.
+func FromRecords(domain string, records []zeit.Record) Zone {
+	zone := Zone{Domain: strings.TrimSuffix(domain, ".")}
+	grouped := make(map[string]int)
+	for i := range records {
+		record := &records[i]
+		entry := Entry{Name: record.Name, Type: record.Type, TTL: record.TTL, Value: record.Value}
+		switch record.Type {
+		case zeit.RecordTypeA, zeit.RecordTypeAAAA:
+			key := record.Name + " " + record.Type + " " + strconv.Itoa(record.TTL)
+			if j, ok := grouped[key]; ok {
+				zone.Entries[j].Values = append(zone.Entries[j].Values, record.Value)
+				continue
+			}
+			grouped[key] = len(zone.Entries)
+			entry.Value, entry.Values = "", []string{record.Value}
+		case zeit.RecordTypeMX:
+			if mx, err := record.MX(); err == nil {
+				entry.Value, entry.MX = "", &MX{Priority: mx.Priority, Host: mx.Host}
+			}
+		case zeit.RecordTypeSRV:
+			if srv, err := record.SRV(); err == nil {
+				entry.Value, entry.SRV = "", &SRV{Priority: srv.Priority, Weight: srv.Weight, Port: srv.Port, Target: srv.Target}
+			}
+		case zeit.RecordTypeCAA:
+			if caa, err := record.CAA(); err == nil {
+				entry.Value, entry.CAA = "", &CAA{Flags: caa.Flags, Tag: caa.Tag, Value: caa.Value}
+			}
+		}
+		zone.Entries = append(zone.Entries, entry)
+	}
+	return zone
+}